@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"news_service/pkg/config"
+	"news_service/pkg/handler"
+	"news_service/pkg/outbox"
+	"news_service/pkg/publisher"
+	"news_service/pkg/service"
+	"news_service/pkg/store"
+	"news_service/pkg/util"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// shutdownTimeout adalah batas waktu menunggu request yang sedang berjalan saat shutdown.
+const shutdownTimeout = 10 * time.Second
+
+type Application struct {
+	Config         *config.AppConfig
+	Router         *mux.Router
+	Logger         *util.Logger
+	Store          store.Store
+	Publisher      *publisher.RabbitMQPublisher
+	Relay          *outbox.Relay
+	ArticleService *service.ArticleService
+}
+
+func NewApplication(cfg *config.AppConfig, logger *util.Logger, rmqPublisher *publisher.RabbitMQPublisher) *Application {
+	app := &Application{
+		Config:    cfg,
+		Router:    mux.NewRouter(),
+		Logger:    logger,
+		Publisher: rmqPublisher,
+	}
+
+	newsStore, err := store.NewStore(cfg.NewsStore, cfg.NewsStorePath)
+	if err != nil {
+		logger.Fatalf("Failed to open news store: %v", err)
+	}
+	app.Store = newsStore
+	logger.Printf("News store '%s' ready with %d articles and %d unpublished events.",
+		newsStore.Backend(), len(newsStore.ListArticles()), newsStore.OutboxLen())
+	if parked := len(newsStore.ParkedOutbox()); parked > 0 {
+		logger.Printf("Warning: %d outbox entries are parked after repeated publish failures; see /admin/outbox/parked.", parked)
+	}
+
+	// Artikel dan event disimpan dalam satu transaksi; relay yang mengirim event ke
+	// RabbitMQ dan menghapusnya dari outbox setelah dikonfirmasi broker.
+	app.Relay = outbox.NewRelay(newsStore, rmqPublisher, logger,
+		cfg.OutboxPollInterval, cfg.OutboxBatchSize, cfg.OutboxMaxAttempts)
+	newsStore.OnOutboxAppend(app.Relay.Notify)
+
+	app.ArticleService = service.NewArticleService(newsStore)
+	articleHandler := handler.NewArticleHandler(app.ArticleService)
+	publisherHandler := handler.NewPublisherHandler(rmqPublisher)
+	outboxHandler := handler.NewOutboxHandler(newsStore)
+
+	app.setupRoutes(articleHandler, publisherHandler, outboxHandler)
+
+	return app
+}
+
+func (a *Application) setupRoutes(
+	articleHandler *handler.ArticleHandler,
+	publisherHandler *handler.PublisherHandler,
+	outboxHandler *handler.OutboxHandler) {
+	a.Router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "Welcome to Go Event-Driven News Service!")
+	}).Methods("GET")
+
+	adminRouter := a.Router.PathPrefix("/admin").Subrouter()
+	adminRouter.HandleFunc("/publisher/stats", publisherHandler.GetStats).Methods("GET")
+	adminRouter.HandleFunc("/outbox/parked", outboxHandler.ListParked).Methods("GET")
+	adminRouter.HandleFunc("/outbox/parked/{seq}", outboxHandler.DiscardParked).Methods("DELETE")
+	adminRouter.HandleFunc("/outbox/parked/{seq}/requeue", outboxHandler.RequeueParked).Methods("POST")
+
+	articleRouter := a.Router.PathPrefix("/articles").Subrouter()
+	articleRouter.HandleFunc("", articleHandler.ListArticles).Methods("GET")
+	articleRouter.HandleFunc("", articleHandler.CreateArticle).Methods("POST")
+	articleRouter.HandleFunc("/{id}", articleHandler.GetArticle).Methods("GET")
+	articleRouter.HandleFunc("/{id}", articleHandler.ReplaceArticle).Methods("PUT")
+	articleRouter.HandleFunc("/{id}", articleHandler.PatchArticle).Methods("PATCH")
+	articleRouter.HandleFunc("/{id}", articleHandler.DeleteArticle).Methods("DELETE")
+}
+
+func (a *Application) StartApplication() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		a.Relay.Run(ctx)
+	}()
+
+	server := &http.Server{Addr: ":" + a.Config.AppPort, Handler: a.Router}
+	go func() {
+		a.Logger.Printf("Starting HTTP server on port %s", a.Config.AppPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.Logger.Fatalf("HTTP server failed: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	a.Logger.Println("Shutting down application gracefully...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		a.Logger.Printf("Error shutting down HTTP server: %v", err)
+	}
+
+	// Event yang belum dikonfirmasi tetap di outbox dan dikirim setelah start berikutnya.
+	<-relayDone
+	a.Publisher.Close()
+	a.Logger.Println("News Service stopped.")
+}
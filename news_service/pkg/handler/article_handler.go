@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"news_service/pkg/model"
+	"news_service/pkg/service"
+	"news_service/pkg/store"
+	"news_service/pkg/util"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// maxBodyBytes membatasi ukuran body request artikel.
+const maxBodyBytes = 1 << 20
+
+// maxPage membatasi nomor halaman GET /articles.
+const maxPage = 10000
+
+type ArticleHandler struct {
+	ArticleService *service.ArticleService
+}
+
+func NewArticleHandler(articleService *service.ArticleService) *ArticleHandler {
+	return &ArticleHandler{
+		ArticleService: articleService,
+	}
+}
+
+// ListArticles menghandle request GET /articles
+func (h *ArticleHandler) ListArticles(w http.ResponseWriter, r *http.Request) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	if page > maxPage {
+		page = maxPage
+	}
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	articles, total := h.ArticleService.ListArticles(page, limit)
+	util.SendSuccessResponse(w, http.StatusOK, "Articles retrieved successfully", map[string]interface{}{
+		"total":    total,
+		"page":     page,
+		"limit":    limit,
+		"articles": articles,
+	})
+}
+
+// GetArticle menghandle request GET /articles/{id}
+func (h *ArticleHandler) GetArticle(w http.ResponseWriter, r *http.Request) {
+	article, err := h.ArticleService.GetArticle(mux.Vars(r)["id"])
+	if err != nil {
+		sendArticleError(w, err, "Failed to retrieve article")
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Article retrieved successfully", article)
+}
+
+// CreateArticle menghandle request POST /articles
+func (h *ArticleHandler) CreateArticle(w http.ResponseWriter, r *http.Request) {
+	var input model.ArticleInput
+	if err := decodeBody(w, r, &input, true); err != nil {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	article, err := h.ArticleService.CreateArticle(input)
+	if err != nil {
+		sendArticleError(w, err, "Failed to create article")
+		return
+	}
+	w.Header().Set("Location", "/articles/"+article.ID)
+	util.SendSuccessResponse(w, http.StatusCreated, "Article created successfully", article)
+}
+
+// ReplaceArticle menghandle request PUT /articles/{id}
+func (h *ArticleHandler) ReplaceArticle(w http.ResponseWriter, r *http.Request) {
+	var input model.ArticleInput
+	if err := decodeBody(w, r, &input, true); err != nil {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	article, err := h.ArticleService.ReplaceArticle(mux.Vars(r)["id"], input)
+	if err != nil {
+		sendArticleError(w, err, "Failed to update article")
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Article updated successfully", article)
+}
+
+// PatchArticle menghandle request PATCH /articles/{id}
+func (h *ArticleHandler) PatchArticle(w http.ResponseWriter, r *http.Request) {
+	var patch map[string]json.RawMessage
+	if err := decodeBody(w, r, &patch, false); err != nil {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	article, err := h.ArticleService.PatchArticle(mux.Vars(r)["id"], patch)
+	if err != nil {
+		sendArticleError(w, err, "Failed to update article")
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Article updated successfully", article)
+}
+
+// DeleteArticle menghandle request DELETE /articles/{id}
+func (h *ArticleHandler) DeleteArticle(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.ArticleService.DeleteArticle(id); err != nil {
+		sendArticleError(w, err, "Failed to delete article")
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Article deleted successfully", map[string]interface{}{"id": id})
+}
+
+// decodeBody membaca body JSON; strict menolak field yang tidak dikenal, termasuk
+// field yang diisi service seperti id dan created_at.
+func decodeBody(w http.ResponseWriter, r *http.Request, target interface{}, strict bool) error {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	if err := decoder.Decode(target); err != nil {
+		return fmt.Errorf("failed to decode JSON body: %w", err)
+	}
+	if decoder.More() {
+		return errors.New("request body must contain a single JSON object")
+	}
+	return nil
+}
+
+// sendArticleError memetakan error service ke status HTTP.
+func sendArticleError(w http.ResponseWriter, err error, message string) {
+	var validationErr *model.ArticleValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid article", validationErr.Problems)
+	case errors.Is(err, store.ErrArticleNotFound):
+		util.SendErrorResponse(w, http.StatusNotFound, "Article not found", err.Error())
+	default:
+		util.SendErrorResponse(w, http.StatusInternalServerError, message, err.Error())
+	}
+}
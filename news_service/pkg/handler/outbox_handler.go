@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"news_service/pkg/store"
+	"news_service/pkg/util"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type OutboxHandler struct {
+	Store store.Store
+}
+
+func NewOutboxHandler(s store.Store) *OutboxHandler {
+	return &OutboxHandler{Store: s}
+}
+
+// ListParked menghandle request GET /admin/outbox/parked: entri yang berhenti dicoba
+// relay setelah gagal terlalu sering, beserta jumlah entri yang masih tertunda.
+func (h *OutboxHandler) ListParked(w http.ResponseWriter, r *http.Request) {
+	parked := h.Store.ParkedOutbox()
+	util.SendSuccessResponse(w, http.StatusOK, "Parked outbox entries retrieved successfully", map[string]interface{}{
+		"pending": h.Store.OutboxLen(),
+		"total":   len(parked),
+		"entries": parked,
+	})
+}
+
+// RequeueParked menghandle request POST /admin/outbox/parked/{seq}/requeue
+func (h *OutboxHandler) RequeueParked(w http.ResponseWriter, r *http.Request) {
+	seq, ok := parseSeq(w, r)
+	if !ok {
+		return
+	}
+	entry, err := h.Store.RequeueParked(seq)
+	if err != nil {
+		sendOutboxError(w, err, seq, "Failed to requeue parked outbox entry")
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Parked outbox entry requeued successfully", entry)
+}
+
+// DiscardParked menghandle request DELETE /admin/outbox/parked/{seq}
+func (h *OutboxHandler) DiscardParked(w http.ResponseWriter, r *http.Request) {
+	seq, ok := parseSeq(w, r)
+	if !ok {
+		return
+	}
+	if err := h.Store.DiscardParked(seq); err != nil {
+		sendOutboxError(w, err, seq, "Failed to discard parked outbox entry")
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Parked outbox entry discarded successfully", map[string]interface{}{"seq": seq})
+}
+
+func parseSeq(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	raw := mux.Vars(r)["seq"]
+	seq, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || seq < 1 {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid outbox sequence number", fmt.Sprintf("'%s' is not a positive integer", raw))
+		return 0, false
+	}
+	return seq, true
+}
+
+func sendOutboxError(w http.ResponseWriter, err error, seq int64, message string) {
+	if errors.Is(err, store.ErrParkedEntryNotFound) {
+		util.SendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Parked outbox entry %d not found", seq), nil)
+		return
+	}
+	util.SendErrorResponse(w, http.StatusInternalServerError, message, err.Error())
+}
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"net/http"
+	"news_service/pkg/publisher"
+	"news_service/pkg/util"
+)
+
+type PublisherHandler struct {
+	Publisher *publisher.RabbitMQPublisher
+}
+
+func NewPublisherHandler(rmqPublisher *publisher.RabbitMQPublisher) *PublisherHandler {
+	return &PublisherHandler{Publisher: rmqPublisher}
+}
+
+// GetStats menghandle request GET /admin/publisher/stats: status koneksi, isi buffer,
+// jumlah event yang dikonfirmasi serta event yang dikembalikan broker sebagai unroutable.
+func (h *PublisherHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	util.SendSuccessResponse(w, http.StatusOK, "Publisher stats retrieved successfully", h.Publisher.Stats())
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Batas panjang field artikel.
+const (
+	MaxTitleLength    = 300
+	MaxAuthorLength   = 100
+	MaxCategoryLength = 50
+	MaxTags           = 20
+	MaxTagLength      = 50
+)
+
+// ArticleInput adalah field artikel yang boleh diisi client lewat POST dan PUT. ID,
+// created_at dan updated_at selalu diisi service; published_at default ke waktu dibuat.
+type ArticleInput struct {
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	Author      string     `json:"author"`
+	Tags        []string   `json:"tags"`
+	Category    string     `json:"category"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// ArticleFields adalah field JSON ArticleInput; hanya field ini yang boleh diubah lewat PATCH.
+var ArticleFields = []string{"title", "content", "author", "tags", "category", "published_at"}
+
+// ArticleValidationError berisi semua pelanggaran yang ditemukan pada input artikel.
+type ArticleValidationError struct {
+	Problems []string
+}
+
+func (e *ArticleValidationError) Error() string {
+	return "invalid article: " + strings.Join(e.Problems, "; ")
+}
+
+// InputFromDocument mengembalikan field artikel yang dapat diubah client. Tags disalin
+// agar perubahan pada input tidak ikut mengubah doc.
+func InputFromDocument(doc DocumentNews) ArticleInput {
+	publishedAt := doc.PublishedAt
+	return ArticleInput{
+		Title:       doc.Title,
+		Content:     doc.Content,
+		Author:      doc.Author,
+		Tags:        slices.Clone(doc.Tags),
+		Category:    doc.Category,
+		PublishedAt: &publishedAt,
+	}
+}
+
+// Normalize merapikan spasi di awal/akhir field teks dan tag.
+func (in *ArticleInput) Normalize() {
+	in.Title = strings.TrimSpace(in.Title)
+	in.Content = strings.TrimSpace(in.Content)
+	in.Author = strings.TrimSpace(in.Author)
+	in.Category = strings.TrimSpace(in.Category)
+	for i, tag := range in.Tags {
+		in.Tags[i] = strings.TrimSpace(tag)
+	}
+}
+
+// Validate memeriksa input setelah Normalize: title, content dan author wajib diisi,
+// panjang field dibatasi, dan tag tidak boleh kosong atau berulang.
+func (in ArticleInput) Validate() error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if in.Title == "" {
+		addProblem("title is required")
+	} else if utf8.RuneCountInString(in.Title) > MaxTitleLength {
+		addProblem("title must be at most %d characters", MaxTitleLength)
+	}
+	if in.Content == "" {
+		addProblem("content is required")
+	}
+	if in.Author == "" {
+		addProblem("author is required")
+	} else if utf8.RuneCountInString(in.Author) > MaxAuthorLength {
+		addProblem("author must be at most %d characters", MaxAuthorLength)
+	}
+	if utf8.RuneCountInString(in.Category) > MaxCategoryLength {
+		addProblem("category must be at most %d characters", MaxCategoryLength)
+	}
+
+	if len(in.Tags) > MaxTags {
+		addProblem("at most %d tags are allowed, got %d", MaxTags, len(in.Tags))
+	}
+	for i, tag := range in.Tags {
+		switch {
+		case tag == "":
+			addProblem("tags[%d] must not be empty", i)
+		case utf8.RuneCountInString(tag) > MaxTagLength:
+			addProblem("tags[%d] must be at most %d characters", i, MaxTagLength)
+		case slices.Contains(in.Tags[:i], tag):
+			addProblem("tag '%s' is listed more than once", tag)
+		}
+	}
+
+	if in.PublishedAt != nil && in.PublishedAt.IsZero() {
+		addProblem("published_at must be a valid timestamp")
+	}
+
+	if len(problems) > 0 {
+		return &ArticleValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// NewArticleID menghasilkan ID artikel acak; formatnya sama dengan event_id.
+func NewArticleID() string {
+	return NewEventID()
+}
@@ -1,12 +1,89 @@
 // pkg/model/event.go
 package model
 
-import "time"
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// Jenis event berita.
+const (
+	EventCreated = "CREATED"
+	EventUpdated = "UPDATED"
+	EventDeleted = "DELETED"
+)
+
+// CurrentSchemaVersion adalah versi skema event yang dikirim service ini. search_service
+// masih menerima v1 (field "timestamp", tanpa event_id/version wajib) selama rollout.
+const CurrentSchemaVersion = 2
 
 type NewsEvent struct {
-	Type      string       `json:"type"`      // "CREATED", "UPDATED", "DELETED"
-	Timestamp time.Time    `json:"timestamp"` // Waktu event terjadi
-	Payload   DocumentNews `json:"payload"`   // Data berita yang terkait
+	SchemaVersion int          `json:"schema_version"`
+	EventID       string       `json:"event_id"`    // Unik per event; dipakai consumer untuk deduplikasi
+	Type          string       `json:"type"`        // "CREATED", "UPDATED", "DELETED"
+	OccurredAt    time.Time    `json:"occurred_at"` // Waktu event terjadi
+	Version       int64        `json:"version"`     // Naik monoton; dipakai consumer untuk menolak event usang
+	Payload       DocumentNews `json:"payload"`     // Data berita yang terkait
+	// UpdateMask berisi field JSON Payload yang diubah oleh UPDATED parsial; kosong berarti dokumen lengkap.
+	UpdateMask []string `json:"update_mask,omitempty"`
+}
+
+// UpdatableFields adalah field yang boleh dipakai di UpdateMask.
+var UpdatableFields = []string{"title", "content", "author", "tags", "category", "published_at", "created_at", "updated_at"}
+
+// lastVersion menyimpan versi terakhir yang dibagikan agar versi tetap naik
+// walaupun dua event dibuat pada nanodetik yang sama.
+var lastVersion atomic.Int64
+
+// NewNewsEvent membuat event dengan OccurredAt sekarang dan Version yang lebih besar
+// dari semua versi sebelumnya di proses ini.
+func NewNewsEvent(eventType string, payload DocumentNews) NewsEvent {
+	now := time.Now()
+	return NewsEvent{
+		SchemaVersion: CurrentSchemaVersion,
+		EventID:       NewEventID(),
+		Type:          eventType,
+		OccurredAt:    now,
+		Version:       nextVersion(now),
+		Payload:       payload,
+	}
+}
+
+// NewEventID menghasilkan ID acak 128-bit dalam bentuk hex.
+func NewEventID() string {
+	b := make([]byte, 16)
+	rand.Read(b) // Sejak Go 1.24 rand.Read tidak pernah mengembalikan error
+	return hex.EncodeToString(b)
+}
+
+// NewPartialUpdateEvent membuat event UPDATED yang hanya menerapkan fields dari changes.
+// changes.ID wajib diisi; field lain di luar fields diabaikan oleh consumer.
+func NewPartialUpdateEvent(changes DocumentNews, fields ...string) (NewsEvent, error) {
+	if len(fields) == 0 {
+		return NewsEvent{}, errors.New("partial update requires at least one field")
+	}
+	event := NewNewsEvent(EventUpdated, changes)
+	event.UpdateMask = fields
+	if err := event.Validate(); err != nil {
+		return NewsEvent{}, err
+	}
+	return event, nil
+}
+
+func nextVersion(now time.Time) int64 {
+	for {
+		last := lastVersion.Load()
+		next := now.UnixNano()
+		if next <= last {
+			next = last + 1
+		}
+		if lastVersion.CompareAndSwap(last, next) {
+			return next
+		}
+	}
 }
 
 type DocumentNews struct {
@@ -15,6 +92,7 @@ type DocumentNews struct {
 	Content     string     `json:"content"`
 	Author      string     `json:"author"`
 	Tags        []string   `json:"tags"`
+	Category    string     `json:"category,omitempty"` // Dipakai juga sebagai bagian routing key
 	CreatedAt   time.Time  `json:"created_at"`
 	PublishedAt time.Time  `json:"published_at"`
 	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ValidationError berisi semua pelanggaran skema yang ditemukan pada sebuah event.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid event: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate memeriksa field wajib event sebelum dikirim; aturannya sama dengan validator
+// di search_service. Semua event membutuhkan payload.id, CREATED dan UPDATED lengkap
+// membutuhkan title, dan update_mask hanya boleh dipakai UPDATED.
+func (e NewsEvent) Validate() error {
+	var problems []string
+	addProblem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if e.SchemaVersion != CurrentSchemaVersion {
+		addProblem("schema_version must be %d, got %d", CurrentSchemaVersion, e.SchemaVersion)
+	}
+	if e.EventID == "" {
+		addProblem("event_id is required")
+	}
+	if e.Version <= 0 {
+		addProblem("version must be a positive number")
+	}
+	if e.OccurredAt.IsZero() {
+		addProblem("occurred_at is required")
+	}
+	if strings.TrimSpace(e.Payload.ID) == "" {
+		addProblem("payload.id is required")
+	}
+
+	switch e.Type {
+	case EventCreated:
+		if len(e.UpdateMask) > 0 {
+			addProblem("update_mask is only allowed on UPDATED events")
+		}
+		if strings.TrimSpace(e.Payload.Title) == "" {
+			addProblem("payload.title is required for CREATED events")
+		}
+	case EventUpdated:
+		for _, field := range e.UpdateMask {
+			if !slices.Contains(UpdatableFields, field) {
+				addProblem("update_mask contains unknown field '%s'", field)
+			}
+		}
+		masked := len(e.UpdateMask) == 0 || slices.Contains(e.UpdateMask, "title")
+		if masked && strings.TrimSpace(e.Payload.Title) == "" {
+			addProblem("payload.title is required when updating the title")
+		}
+	case EventDeleted:
+		if len(e.UpdateMask) > 0 {
+			addProblem("update_mask is only allowed on UPDATED events")
+		}
+	case "":
+		addProblem("type is required")
+	default:
+		addProblem("unknown event type '%s'", e.Type)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
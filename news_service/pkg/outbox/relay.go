@@ -0,0 +1,155 @@
+// Package outbox memindahkan event dari outbox store ke RabbitMQ. Event hanya dihapus
+// dari outbox setelah broker mengonfirmasinya, sehingga crash di titik mana pun paling
+// buruk menghasilkan pengiriman ulang (dikenali consumer lewat event_id), bukan event hilang.
+package outbox
+
+import (
+	"context"
+	"errors"
+	"news_service/pkg/publisher"
+	"news_service/pkg/store"
+	"news_service/pkg/util"
+	"time"
+)
+
+// Relay membaca entri outbox yang tertunda secara berkala atau saat dibangunkan
+// lewat Notify, lalu mempublikasikannya dengan publisher confirms. Entri yang gagal
+// maxAttempts kali di-park agar terlihat oleh operator dan tidak menahan artikelnya.
+type Relay struct {
+	store        store.Store
+	publisher    *publisher.RabbitMQPublisher
+	logger       *util.Logger
+	pollInterval time.Duration
+	batchSize    int
+	maxAttempts  int
+	wake         chan struct{}
+}
+
+func NewRelay(s store.Store, p *publisher.RabbitMQPublisher, logger *util.Logger, pollInterval time.Duration, batchSize, maxAttempts int) *Relay {
+	return &Relay{
+		store:        s,
+		publisher:    p,
+		logger:       logger,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Notify membangunkan relay tanpa memblokir, mis. setelah transaksi menambah event.
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run memproses outbox sampai ctx selesai.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Ulangi selama semua entri terkirim agar backlog cepat habis.
+		for r.relayBatch(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// relayBatch mempublikasikan entri tertua dari setiap artikel (paling banyak batchSize
+// artikel) lalu menandai yang sudah dikonfirmasi sebagai terkirim. Event satu artikel
+// dikirim berurutan karena entri berikutnya baru diambil setelah entri sebelumnya
+// dikonfirmasi atau di-park. Mengembalikan true jika semua entri berhasil, artinya
+// kemungkinan masih ada entri lain yang bisa langsung dikirim.
+func (r *Relay) relayBatch(ctx context.Context) bool {
+	wave := r.store.PendingOutbox(r.batchSize)
+	if len(wave) == 0 {
+		return false
+	}
+
+	confirmed, aborted := r.publishWave(ctx, wave)
+	var sent []int64
+	for i, entry := range wave {
+		if confirmed[i] {
+			sent = append(sent, entry.Seq)
+		}
+	}
+	if !r.markSent(sent) || aborted {
+		return false
+	}
+	return len(sent) == len(wave)
+}
+
+// publishWave mengirim entri (paling banyak satu per artikel) lalu menunggu konfirmasinya.
+// confirmed[i] bernilai true jika wave[i] dikonfirmasi broker; aborted bernilai true
+// jika ctx selesai sebelum semua hasil diterima.
+func (r *Relay) publishWave(ctx context.Context, wave []store.OutboxEntry) (confirmed []bool, aborted bool) {
+	confirmed = make([]bool, len(wave))
+	results := make([]<-chan error, len(wave))
+	for i, entry := range wave {
+		result, err := r.publisher.PublishConfirmed(entry.Event)
+		if err != nil {
+			r.recordFailure(entry, err)
+			break // Mis. buffer penuh; sisa entri dicoba lagi di batch berikutnya
+		}
+		results[i] = result
+	}
+
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			// Entri yang belum dikonfirmasi tetap di outbox dan dikirim ulang saat start.
+			return confirmed, true
+		case err := <-result:
+			if err != nil {
+				r.recordFailure(wave[i], err)
+				continue
+			}
+			confirmed[i] = true
+		}
+	}
+	return confirmed, false
+}
+
+func (r *Relay) markSent(seqs []int64) bool {
+	if len(seqs) == 0 {
+		return true
+	}
+	if err := r.store.MarkSent(seqs...); err != nil {
+		r.logger.Printf("Failed to mark %d outbox entries as sent: %v. They will be published again.", len(seqs), err)
+		return false
+	}
+	return true
+}
+
+func (r *Relay) recordFailure(entry store.OutboxEntry, cause error) {
+	if errors.Is(cause, publisher.ErrPublisherClosed) {
+		return // Shutdown; entri tetap di outbox tanpa dihitung sebagai kegagalan
+	}
+	attempt := entry.Attempts + 1
+	// Buffer penuh bukan kesalahan entri ini, jadi tidak membuatnya di-park.
+	if attempt >= r.maxAttempts && !errors.Is(cause, publisher.ErrBufferFull) {
+		r.logger.Printf("Outbox entry %d (event %s, '%s' for ID %s) failed %d times and is parked: %v",
+			entry.Seq, entry.Event.EventID, entry.Event.Type, entry.Event.Payload.ID, attempt, cause)
+		if err := r.store.ParkOutbox(entry.Seq, cause); err != nil {
+			r.logger.Printf("Failed to park outbox entry %d: %v", entry.Seq, err)
+		}
+		return
+	}
+	r.logger.Printf("Outbox entry %d (event %s, '%s' for ID %s) not published (attempt %d of %d): %v",
+		entry.Seq, entry.Event.EventID, entry.Event.Type, entry.Event.Payload.ID, attempt, r.maxAttempts, cause)
+	if err := r.store.RecordFailure(entry.Seq, cause); err != nil {
+		r.logger.Printf("Failed to record outbox failure for entry %d: %v", entry.Seq, err)
+	}
+}
@@ -0,0 +1,94 @@
+package publisher
+
+import (
+	"encoding/json"
+	"fmt"
+	"news_service/pkg/model"
+	"strings"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// Format pesan yang dikirim publisher (CLOUDEVENTS_MODE).
+const (
+	ModeLegacy     = "legacy"     // JSON NewsEvent apa adanya
+	ModeStructured = "structured" // CloudEvents: atribut dan data di body
+	ModeBinary     = "binary"     // CloudEvents: atribut di header "cloudEvents:*", body berisi data
+)
+
+const (
+	cloudEventsSpecVersion  = "1.0"
+	cloudEventsContentType  = "application/cloudevents+json"
+	cloudEventsHeaderPrefix = "cloudEvents:"
+	cloudEventTypePrefix    = "com.news.article."
+)
+
+// cloudEventData adalah bagian NewsEvent yang tidak diwakili atribut CloudEvents.
+type cloudEventData struct {
+	SchemaVersion int                `json:"schema_version"`
+	Version       int64              `json:"version"`
+	Payload       model.DocumentNews `json:"payload"`
+	UpdateMask    []string           `json:"update_mask,omitempty"`
+}
+
+// cloudEventType mengubah "CREATED" menjadi "com.news.article.created".
+func cloudEventType(eventType string) string {
+	return cloudEventTypePrefix + strings.ToLower(eventType)
+}
+
+// buildPublishing menyusun pesan AMQP untuk event sesuai mode. Atribut CloudEvents:
+// id = event_id, source = source, type = com.news.article.<type>, subject = ID artikel,
+// time = occurred_at.
+func buildPublishing(event model.NewsEvent, mode, source string) (amqp091.Publishing, error) {
+	publishing := amqp091.Publishing{
+		MessageId:    event.EventID,
+		Timestamp:    event.OccurredAt,
+		DeliveryMode: amqp091.Persistent,
+	}
+
+	data := cloudEventData{
+		SchemaVersion: event.SchemaVersion,
+		Version:       event.Version,
+		Payload:       event.Payload,
+		UpdateMask:    event.UpdateMask,
+	}
+	attributes := map[string]string{
+		"specversion": cloudEventsSpecVersion,
+		"id":          event.EventID,
+		"source":      source,
+		"type":        cloudEventType(event.Type),
+		"subject":     event.Payload.ID,
+		"time":        event.OccurredAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	var err error
+	switch mode {
+	case ModeLegacy:
+		publishing.ContentType = "application/json"
+		publishing.Body, err = json.Marshal(event)
+	case ModeStructured:
+		envelope := make(map[string]interface{}, len(attributes)+2)
+		for name, value := range attributes {
+			envelope[name] = value
+		}
+		envelope["datacontenttype"] = "application/json"
+		envelope["data"] = data
+		publishing.ContentType = cloudEventsContentType
+		publishing.Body, err = json.Marshal(envelope)
+	case ModeBinary:
+		headers := amqp091.Table{}
+		for name, value := range attributes {
+			headers[cloudEventsHeaderPrefix+name] = value
+		}
+		publishing.Headers = headers
+		publishing.ContentType = "application/json"
+		publishing.Body, err = json.Marshal(data)
+	default:
+		return publishing, fmt.Errorf("unknown publish mode '%s'", mode)
+	}
+	if err != nil {
+		return publishing, fmt.Errorf("failed to marshal NewsEvent to JSON: %w", err)
+	}
+	return publishing, nil
+}
@@ -0,0 +1,281 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"news_service/pkg/model"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// maxInFlight adalah jumlah pesan yang boleh menunggu konfirmasi sekaligus.
+const maxInFlight = 64
+
+var (
+	// ErrBufferFull dikembalikan jika buffer lokal sudah penuh, mis. saat broker lama tidak tersedia.
+	ErrBufferFull = errors.New("publish buffer is full")
+	// ErrReturned dikembalikan jika broker mengembalikan pesan karena tidak ada queue tujuan.
+	ErrReturned = errors.New("message was returned by the broker")
+	// ErrPublisherClosed dikembalikan untuk event yang belum terkirim saat publisher ditutup.
+	ErrPublisherClosed = errors.New("publisher is closed")
+)
+
+// outboundMessage adalah event di buffer lokal yang menunggu dikirim atau dikonfirmasi.
+type outboundMessage struct {
+	event      model.NewsEvent
+	exchange   string
+	routingKey string
+	publishing amqp091.Publishing
+	// result menerima hasil akhir: nil jika di-ack broker.
+	result chan error
+	// confirm bernilai nil selama pesan belum dikirim di channel saat ini.
+	confirm *amqp091.DeferredConfirmation
+	sentAt  time.Time
+}
+
+// enqueue menambahkan pesan ke akhir buffer. Pesan dikirim berurutan oleh runSender.
+func (p *RabbitMQPublisher) enqueue(msg *outboundMessage) error {
+	p.bufMu.Lock()
+	if p.closed {
+		p.bufMu.Unlock()
+		return ErrPublisherClosed
+	}
+	if len(p.pending) >= p.cfg.PublishBufferSize {
+		p.bufMu.Unlock()
+		return fmt.Errorf("%w (%d events waiting)", ErrBufferFull, p.cfg.PublishBufferSize)
+	}
+	p.pending = append(p.pending, msg)
+	p.bufMu.Unlock()
+
+	p.notify()
+	return nil
+}
+
+// Buffered mengembalikan jumlah event yang belum dikonfirmasi broker.
+func (p *RabbitMQPublisher) Buffered() int {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+	return len(p.pending)
+}
+
+// notify membangunkan runSender tanpa memblokir.
+func (p *RabbitMQPublisher) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runSender mengirim isi buffer secara berurutan dengan publisher confirms dan
+// mandatory. Pesan baru dihapus dari buffer setelah di-ack broker atau dikembalikan
+// sebagai unroutable; nack, timeout konfirmasi dan putusnya koneksi membuat semua
+// pesan yang belum dikonfirmasi dikirim ulang dari awal setelah channel tersedia,
+// sehingga urutan tetap terjaga. Pengiriman ulang dapat menghasilkan duplikat yang
+// dikenali consumer lewat event_id.
+func (p *RabbitMQPublisher) runSender() {
+	for {
+		select {
+		case <-p.done:
+			return
+		default:
+		}
+
+		ch, returns, ok := p.confirmChannel()
+		if !ok {
+			p.waitForWork(p.cfg.RabbitMQReconnectMinDelay)
+			continue
+		}
+
+		if err := p.sendPending(ch); err != nil {
+			p.logger.Printf("Publishing buffered events failed: %v. Retrying after reconnect.", err)
+			p.resetInFlight()
+			p.waitForWork(p.cfg.RabbitMQReconnectMinDelay)
+			continue
+		}
+
+		head := p.head()
+		if head == nil || head.confirm == nil {
+			p.waitForWork(0)
+			continue
+		}
+
+		timeout := time.NewTimer(time.Until(head.sentAt.Add(p.cfg.PublishConfirmTimeout)))
+		select {
+		case <-p.done:
+			timeout.Stop()
+			return
+		case <-p.wake:
+		case <-head.confirm.Done():
+			p.settleHead(head, returns)
+		case <-timeout.C:
+			p.logger.Printf("No confirm for event %s within %s. Re-sending unconfirmed events.",
+				head.event.EventID, p.cfg.PublishConfirmTimeout)
+			p.resetInFlight()
+		}
+		timeout.Stop()
+	}
+}
+
+// waitForWork menunggu pesan baru atau reconnect; jika delay > 0 juga berhenti
+// menunggu setelah delay berlalu.
+func (p *RabbitMQPublisher) waitForWork(delay time.Duration) {
+	var timeout <-chan time.Time
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-p.done:
+	case <-p.wake:
+	case <-timeout:
+	}
+}
+
+// sendPending mengirim pesan yang belum dikirim di channel saat ini, sampai maxInFlight
+// pesan menunggu konfirmasi. bufMu tidak dipegang selama publish ke jaringan agar
+// enqueue dan Buffered tidak ikut tertahan saat broker lambat.
+func (p *RabbitMQPublisher) sendPending(ch *amqp091.Channel) error {
+	for _, msg := range p.unsent() {
+		ctx, cancel := context.WithTimeout(context.Background(), p.cfg.PublishConfirmTimeout)
+		confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx,
+			msg.exchange,
+			msg.routingKey,
+			true, // mandatory: pesan tanpa queue tujuan dikembalikan lewat NotifyReturn
+			false,
+			msg.publishing)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", msg.event.EventID, err)
+		}
+
+		p.bufMu.Lock()
+		msg.confirm = confirm
+		msg.sentAt = time.Now()
+		p.bufMu.Unlock()
+	}
+	return nil
+}
+
+// unsent menyalin pesan terdepan yang belum dikirim, sebanyak sisa kuota maxInFlight.
+// Pesan yang sedang menunggu konfirmasi selalu berada di awal buffer, dan confirm
+// hanya diubah oleh runSender, sehingga salinan ini tetap berurutan.
+func (p *RabbitMQPublisher) unsent() []*outboundMessage {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+
+	inFlight := 0
+	var msgs []*outboundMessage
+	for _, msg := range p.pending {
+		if msg.confirm != nil {
+			inFlight++
+			continue
+		}
+		if inFlight+len(msgs) >= maxInFlight {
+			break
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func (p *RabbitMQPublisher) head() *outboundMessage {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+	if len(p.pending) == 0 {
+		return nil
+	}
+	return p.pending[0]
+}
+
+// settleHead memproses konfirmasi pesan terdepan. Broker mengirim basic.return sebelum
+// basic.ack untuk pesan yang sama, sehingga semua return yang relevan sudah ada di
+// channel returns saat konfirmasinya selesai.
+func (p *RabbitMQPublisher) settleHead(head *outboundMessage, returns <-chan amqp091.Return) {
+	p.drainReturns(returns)
+
+	if ret, ok := p.returned[head.publishing.MessageId]; ok {
+		delete(p.returned, head.publishing.MessageId)
+		if !p.popHead(head) {
+			return
+		}
+		err := fmt.Errorf("%w: %d %s (exchange '%s', routing key '%s')",
+			ErrReturned, ret.ReplyCode, ret.ReplyText, ret.Exchange, ret.RoutingKey)
+		p.logger.Printf("Event %s ('%s' for ID %s) was not delivered: %v", head.event.EventID, head.event.Type, head.event.Payload.ID, err)
+		p.recordReturned(head, err)
+		complete(head, err)
+		return
+	}
+
+	if !head.confirm.Acked() {
+		p.logger.Printf("Broker did not confirm event %s. Re-sending unconfirmed events.", head.event.EventID)
+		p.resetInFlight()
+		return
+	}
+
+	if !p.popHead(head) {
+		return
+	}
+	p.logger.Printf(" [x] Confirmed '%s' event %s for ID: %s (%s, routing key: %s)",
+		head.event.Type, head.event.EventID, head.event.Payload.ID, p.cfg.CloudEventsMode, head.routingKey)
+	p.statsMu.Lock()
+	p.confirmed++
+	p.statsMu.Unlock()
+	complete(head, nil)
+}
+
+func (p *RabbitMQPublisher) drainReturns(returns <-chan amqp091.Return) {
+	for {
+		select {
+		case ret, ok := <-returns:
+			if !ok {
+				return
+			}
+			p.returned[ret.MessageId] = ret
+		default:
+			return
+		}
+	}
+}
+
+// popHead menghapus head dari buffer. Mengembalikan false jika head sudah tidak ada
+// (mis. buffer sudah dikosongkan oleh Close), sehingga hasilnya tidak dilaporkan dua kali.
+func (p *RabbitMQPublisher) popHead(head *outboundMessage) bool {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+	if len(p.pending) == 0 || p.pending[0] != head {
+		return false
+	}
+	p.pending[0] = nil
+	p.pending = p.pending[1:]
+	return true
+}
+
+// resetInFlight menandai semua pesan sebagai belum dikirim agar dikirim ulang berurutan.
+func (p *RabbitMQPublisher) resetInFlight() {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+	for _, msg := range p.pending {
+		msg.confirm = nil
+	}
+	clear(p.returned)
+}
+
+// failPending menggagalkan semua pesan yang tersisa di buffer, dipakai saat Close.
+func (p *RabbitMQPublisher) failPending() int {
+	p.bufMu.Lock()
+	defer p.bufMu.Unlock()
+	p.closed = true
+	count := len(p.pending)
+	for _, msg := range p.pending {
+		complete(msg, ErrPublisherClosed)
+	}
+	p.pending = nil
+	return count
+}
+
+func complete(msg *outboundMessage, err error) {
+	msg.result <- err
+}
@@ -1,63 +1,210 @@
 package publisher
 
 import (
-	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"news_service/pkg/config"
 	"news_service/pkg/model"
 	"news_service/pkg/util"
+	"sync"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
 )
 
+// Status koneksi publisher.
 const (
-	QueueName = "news_queue"
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting"
+	StateClosed       = "closed"
 )
 
 type RabbitMQPublisher struct {
+	mu      sync.RWMutex
 	conn    *amqp091.Connection
 	channel *amqp091.Channel
+	returns <-chan amqp091.Return
+	state   string
+	cfg     *config.AppConfig
 	logger  *util.Logger
+	done    chan struct{}
+	wake    chan struct{}
+
+	// Buffer lokal berisi event yang belum dikonfirmasi broker, dalam urutan publish.
+	bufMu   sync.Mutex
+	pending []*outboundMessage
+	closed  bool
+	// returned hanya diakses oleh runSender.
+	returned map[string]amqp091.Return
+
+	// Statistik publish sejak publisher dibuat, lihat Stats.
+	statsMu       sync.Mutex
+	confirmed     int64
+	returnedCount int64
+	recentReturns []ReturnedEvent
 }
 
 func NewRabbitMQPublisher(cfg *config.AppConfig, logger *util.Logger) (*RabbitMQPublisher, error) {
-	conn, err := amqp091.Dial(cfg.RabbitMQURL)
+	p := &RabbitMQPublisher{
+		cfg:      cfg,
+		logger:   logger,
+		done:     make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+		returned: make(map[string]amqp091.Return),
+	}
+	if err := p.connect(); err != nil {
+		return nil, err
+	}
+
+	go p.supervise()
+	go p.runSender()
+	return p, nil
+}
+
+// connect membuka koneksi dan channel baru dalam confirm mode lalu mendeklarasikan
+// ulang topologi.
+func (p *RabbitMQPublisher) connect() error {
+	conn, err := amqp091.Dial(p.cfg.RabbitMQURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
 	ch, err := conn.Channel()
 	if err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("failed to open a channel: %w", err)
+		return fmt.Errorf("failed to open a channel: %w", err)
 	}
 
-	_, err = ch.QueueDeclare(
-		QueueName,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	returns := ch.NotifyReturn(make(chan amqp091.Return, maxInFlight))
+
+	if err := p.declareTopology(ch); err != nil {
 		ch.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to declare queue '%s': %w", QueueName, err)
+		return err
+	}
+
+	p.mu.Lock()
+	if p.state == StateClosed {
+		// Close dipanggil saat koneksi ini dibuat; jangan pasang koneksi yang tidak akan ditutup.
+		p.mu.Unlock()
+		ch.Close()
+		conn.Close()
+		return ErrPublisherClosed
+	}
+	p.conn = conn
+	p.channel = ch
+	p.returns = returns
+	p.state = StateConnected
+	p.mu.Unlock()
+	p.notify() // Kirim ulang isi buffer setelah reconnect
+
+	if p.cfg.RabbitMQExchange == "" {
+		p.logger.Printf("Successfully connected to RabbitMQ and declared queue '%s' (default exchange)", QueueName)
+	} else {
+		p.logger.Printf("Successfully connected to RabbitMQ and declared topic exchange '%s'", p.cfg.RabbitMQExchange)
+	}
+	return nil
+}
+
+// supervise memantau NotifyClose pada koneksi dan channel, lalu menyambung ulang
+// dengan backoff ber-jitter sampai berhasil atau publisher ditutup.
+func (p *RabbitMQPublisher) supervise() {
+	backoff := util.Backoff{Min: p.cfg.RabbitMQReconnectMinDelay, Max: p.cfg.RabbitMQReconnectMaxDelay}
+
+	for {
+		p.mu.RLock()
+		conn, ch := p.conn, p.channel
+		p.mu.RUnlock()
+
+		connClosed := conn.NotifyClose(make(chan *amqp091.Error, 1))
+		chanClosed := ch.NotifyClose(make(chan *amqp091.Error, 1))
+
+		select {
+		case <-p.done:
+			return
+		case amqpErr := <-connClosed:
+			p.logger.Printf("RabbitMQ connection closed: %v", amqpErr)
+		case amqpErr := <-chanClosed:
+			p.logger.Printf("RabbitMQ channel closed: %v", amqpErr)
+			conn.Close()
+		}
+
+		if p.State() == StateClosed {
+			return
+		}
+		p.setState(StateReconnecting)
+
+		for {
+			delay := backoff.Next()
+			p.logger.Printf("Reconnecting to RabbitMQ in %s...", delay)
+			select {
+			case <-p.done:
+				return
+			case <-time.After(delay):
+			}
+
+			if err := p.connect(); errors.Is(err, ErrPublisherClosed) {
+				return
+			} else if err != nil {
+				p.logger.Printf("Reconnect to RabbitMQ failed: %v", err)
+				continue
+			}
+			backoff.Reset()
+			break
+		}
 	}
+}
 
-	logger.Printf("Successfully connected to RabbitMQ and declared queue '%s'", QueueName)
+// State mengembalikan status koneksi publisher: connected, reconnecting atau closed.
+func (p *RabbitMQPublisher) State() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.state
+}
+
+// IsConnected bernilai true jika publisher siap mengirim event.
+func (p *RabbitMQPublisher) IsConnected() bool {
+	return p.State() == StateConnected
+}
 
-	return &RabbitMQPublisher{
-		conn:    conn,
-		channel: ch,
-		logger:  logger,
-	}, nil
+// confirmChannel mengembalikan channel aktif beserta channel return-nya.
+func (p *RabbitMQPublisher) confirmChannel() (*amqp091.Channel, <-chan amqp091.Return, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.state != StateConnected {
+		return nil, nil, false
+	}
+	return p.channel, p.returns, true
 }
 
+func (p *RabbitMQPublisher) setState(state string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state != StateClosed {
+		p.state = state
+	}
+}
+
+// Close menghentikan publisher. Event yang masih di buffer dan belum dikonfirmasi
+// dianggap gagal dengan ErrPublisherClosed.
 func (p *RabbitMQPublisher) Close() {
+	if count := p.failPending(); count > 0 {
+		p.logger.Printf("Warning: %d buffered events were not confirmed before shutdown.", count)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state == StateClosed {
+		return
+	}
+
+	p.state = StateClosed
+	close(p.done)
 	if p.channel != nil {
 		p.channel.Close()
 		p.logger.Println("RabbitMQ channel closed.")
@@ -68,29 +215,31 @@ func (p *RabbitMQPublisher) Close() {
 	}
 }
 
-func (p *RabbitMQPublisher) PublishNewsEvent(ctx context.Context, event model.NewsEvent) error {
-	body, err := json.Marshal(event)
-	if err != nil {
-		return fmt.Errorf("failed to marshal NewsEvent to JSON: %w", err)
-	}
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	err = p.channel.PublishWithContext(ctx,
-		"",
-		QueueName,
-		false,
-		false,
-		amqp091.Publishing{
-			ContentType:  "application/json",
-			Body:         body,
-			DeliveryMode: amqp091.Persistent,
-		})
+// PublishConfirmed memvalidasi event lalu memasukkannya ke buffer lokal untuk dikirim
+// dengan publisher confirms, dan mengembalikan channel yang menerima hasil konfirmasi
+// broker (nil jika di-ack). Selama broker tidak tersedia event tetap di buffer (sampai
+// PUBLISH_BUFFER_SIZE) dan dikirim berurutan setelah koneksi pulih. Satu-satunya
+// pemanggil adalah relay outbox, sehingga setiap event selalu melewati outbox.
+func (p *RabbitMQPublisher) PublishConfirmed(event model.NewsEvent) (<-chan error, error) {
+	if err := event.Validate(); err != nil {
+		return nil, err
+	}
+
+	publishing, err := buildPublishing(event, p.cfg.CloudEventsMode, p.cfg.CloudEventsSource)
 	if err != nil {
-		return fmt.Errorf("failed to publish message to RabbitMQ: %w", err)
+		return nil, err
 	}
 
-	p.logger.Printf(" [x] Sent '%s' event for ID: %s", event.Type, event.Payload.ID)
-	return nil
+	exchange, routingKey := p.destination(event)
+	msg := &outboundMessage{
+		event:      event,
+		exchange:   exchange,
+		routingKey: routingKey,
+		publishing: publishing,
+		result:     make(chan error, 1),
+	}
+	if err := p.enqueue(msg); err != nil {
+		return nil, err
+	}
+	return msg.result, nil
 }
@@ -0,0 +1,64 @@
+package publisher
+
+import (
+	"fmt"
+	"news_service/pkg/model"
+	"strings"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// QueueName adalah queue tujuan pada mode kompatibilitas default exchange
+// (RABBITMQ_EXCHANGE kosong).
+const QueueName = "news_queue"
+
+// uncategorized dipakai pada routing key untuk artikel tanpa kategori.
+const uncategorized = "uncategorized"
+
+// declareTopology mendeklarasikan topic exchange yang durable. Queue dan binding-nya
+// dideklarasikan oleh masing-masing consumer. Pada mode kompatibilitas, queue lama
+// dideklarasikan seperti sebelumnya.
+func (p *RabbitMQPublisher) declareTopology(ch *amqp091.Channel) error {
+	if p.cfg.RabbitMQExchange == "" {
+		if _, err := ch.QueueDeclare(QueueName, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare queue '%s': %w", QueueName, err)
+		}
+		return nil
+	}
+
+	if err := ch.ExchangeDeclare(p.cfg.RabbitMQExchange, amqp091.ExchangeTopic, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare exchange '%s': %w", p.cfg.RabbitMQExchange, err)
+	}
+	return nil
+}
+
+// destination mengembalikan exchange dan routing key untuk event.
+func (p *RabbitMQPublisher) destination(event model.NewsEvent) (string, string) {
+	if p.cfg.RabbitMQExchange == "" {
+		return "", QueueName
+	}
+	return p.cfg.RabbitMQExchange, RoutingKey(event)
+}
+
+// RoutingKey menyusun routing key "news.article.<type>.<category>", mis.
+// "news.article.created.olahraga". Kategori dinormalisasi menjadi huruf kecil dan
+// karakter di luar [a-z0-9_-] diganti "-" karena "." memisahkan kata pada topic exchange.
+func RoutingKey(event model.NewsEvent) string {
+	return "news.article." + strings.ToLower(event.Type) + "." + routingSegment(event.Payload.Category)
+}
+
+func routingSegment(value string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(value)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	if b.Len() == 0 {
+		return uncategorized
+	}
+	return b.String()
+}
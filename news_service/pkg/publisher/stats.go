@@ -0,0 +1,61 @@
+package publisher
+
+import "time"
+
+// maxRecentReturns adalah jumlah event unroutable terakhir yang disimpan untuk Stats.
+const maxRecentReturns = 20
+
+// ReturnedEvent adalah event yang dikembalikan broker karena tidak ada queue tujuan.
+// Selain dikembalikan ke relay, event ini dicatat di Stats agar terlihat oleh operator.
+type ReturnedEvent struct {
+	EventID    string    `json:"event_id"`
+	Type       string    `json:"type"`
+	ArticleID  string    `json:"article_id"`
+	RoutingKey string    `json:"routing_key"`
+	Reason     string    `json:"reason"`
+	ReturnedAt time.Time `json:"returned_at"`
+}
+
+// PublisherStats adalah ringkasan publish untuk endpoint admin.
+type PublisherStats struct {
+	State         string          `json:"state"`
+	Buffered      int             `json:"buffered"`
+	Confirmed     int64           `json:"confirmed"`
+	Returned      int64           `json:"returned"`
+	RecentReturns []ReturnedEvent `json:"recent_returns"`
+}
+
+// Stats mengembalikan jumlah event yang dikonfirmasi dan dikembalikan broker sejak
+// publisher dibuat, beserta event unroutable terakhir.
+func (p *RabbitMQPublisher) Stats() PublisherStats {
+	p.statsMu.Lock()
+	recent := make([]ReturnedEvent, len(p.recentReturns))
+	copy(recent, p.recentReturns)
+	stats := PublisherStats{
+		Confirmed:     p.confirmed,
+		Returned:      p.returnedCount,
+		RecentReturns: recent,
+	}
+	p.statsMu.Unlock()
+
+	stats.State = p.State()
+	stats.Buffered = p.Buffered()
+	return stats
+}
+
+func (p *RabbitMQPublisher) recordReturned(msg *outboundMessage, cause error) {
+	p.statsMu.Lock()
+	defer p.statsMu.Unlock()
+	p.returnedCount++
+	p.recentReturns = append(p.recentReturns, ReturnedEvent{
+		EventID:    msg.event.EventID,
+		Type:       msg.event.Type,
+		ArticleID:  msg.event.Payload.ID,
+		RoutingKey: msg.routingKey,
+		Reason:     cause.Error(),
+		ReturnedAt: time.Now(),
+	})
+	if len(p.recentReturns) > maxRecentReturns {
+		p.recentReturns = p.recentReturns[len(p.recentReturns)-maxRecentReturns:]
+	}
+}
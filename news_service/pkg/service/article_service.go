@@ -0,0 +1,240 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"news_service/pkg/model"
+	"news_service/pkg/store"
+	"slices"
+	"time"
+)
+
+// ArticleService mengelola artikel. Setiap perubahan disimpan bersama event
+// CREATED/UPDATED/DELETED-nya dalam satu transaksi store; relay outbox yang
+// mempublikasikan event tersebut ke RabbitMQ.
+type ArticleService struct {
+	store store.Store
+}
+
+func NewArticleService(s store.Store) *ArticleService {
+	return &ArticleService{store: s}
+}
+
+// GetArticle mengembalikan artikel berdasarkan ID atau store.ErrArticleNotFound.
+func (s *ArticleService) GetArticle(id string) (model.DocumentNews, error) {
+	doc, ok := s.store.GetArticle(id)
+	if !ok {
+		return model.DocumentNews{}, fmt.Errorf("%w: %s", store.ErrArticleNotFound, id)
+	}
+	return doc, nil
+}
+
+// ListArticles mengembalikan satu halaman artikel (urut ID) beserta jumlah seluruh artikel.
+func (s *ArticleService) ListArticles(page, limit int) ([]model.DocumentNews, int) {
+	docs := s.store.ListArticles()
+	if page < 1 || limit < 1 || page-1 > len(docs)/limit {
+		// Halaman di luar jangkauan; dicek sebelum perkalian agar offset tidak overflow.
+		return []model.DocumentNews{}, len(docs)
+	}
+	start := min((page-1)*limit, len(docs))
+	end := min(start+limit, len(docs))
+	return docs[start:end], len(docs)
+}
+
+// CreateArticle menyimpan artikel baru dengan ID dan timestamp dari service.
+func (s *ArticleService) CreateArticle(input model.ArticleInput) (model.DocumentNews, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return model.DocumentNews{}, err
+	}
+
+	now := time.Now().UTC()
+	doc := applyInput(model.DocumentNews{
+		ID:          model.NewArticleID(),
+		CreatedAt:   now,
+		PublishedAt: now,
+	}, input)
+
+	err := s.store.Update(func(tx *store.Tx) error {
+		tx.PutArticle(doc)
+		_, err := tx.AppendEvent(model.NewNewsEvent(model.EventCreated, doc))
+		return err
+	})
+	if err != nil {
+		return model.DocumentNews{}, err
+	}
+	return doc, nil
+}
+
+// ReplaceArticle mengganti semua field yang dapat diubah client (PUT) dan mengirim
+// UPDATED dengan dokumen lengkap. created_at tetap; published_at tetap jika tidak diisi.
+func (s *ArticleService) ReplaceArticle(id string, input model.ArticleInput) (model.DocumentNews, error) {
+	input.Normalize()
+	if err := input.Validate(); err != nil {
+		return model.DocumentNews{}, err
+	}
+
+	var doc model.DocumentNews
+	err := s.store.Update(func(tx *store.Tx) error {
+		existing, ok := tx.GetArticle(id)
+		if !ok {
+			return fmt.Errorf("%w: %s", store.ErrArticleNotFound, id)
+		}
+		doc = applyInput(existing, input)
+		now := time.Now().UTC()
+		doc.UpdatedAt = &now
+
+		tx.PutArticle(doc)
+		_, err := tx.AppendEvent(model.NewNewsEvent(model.EventUpdated, doc))
+		return err
+	})
+	if err != nil {
+		return model.DocumentNews{}, err
+	}
+	return doc, nil
+}
+
+// PatchArticle hanya mengubah field yang ada di patch (PATCH) dan mengirim UPDATED
+// parsial dengan update_mask berisi field tersebut ditambah updated_at.
+func (s *ArticleService) PatchArticle(id string, patch map[string]json.RawMessage) (model.DocumentNews, error) {
+	fields, err := patchFields(patch)
+	if err != nil {
+		return model.DocumentNews{}, err
+	}
+
+	var doc model.DocumentNews
+	err = s.store.Update(func(tx *store.Tx) error {
+		existing, ok := tx.GetArticle(id)
+		if !ok {
+			return fmt.Errorf("%w: %s", store.ErrArticleNotFound, id)
+		}
+
+		input, err := applyPatch(model.InputFromDocument(existing), patch)
+		if err != nil {
+			return err
+		}
+		input.Normalize()
+		if err := input.Validate(); err != nil {
+			return err
+		}
+
+		doc = applyInput(existing, input)
+		now := time.Now().UTC()
+		doc.UpdatedAt = &now
+		tx.PutArticle(doc)
+
+		// Payload memuat dokumen lengkap agar routing key memuat kategori terbaru;
+		// consumer hanya menerapkan field di update_mask.
+		event, err := model.NewPartialUpdateEvent(doc, append(fields, "updated_at")...)
+		if err != nil {
+			return err
+		}
+		_, err = tx.AppendEvent(event)
+		return err
+	})
+	if err != nil {
+		return model.DocumentNews{}, err
+	}
+	return doc, nil
+}
+
+// DeleteArticle menghapus artikel dan mengirim DELETED.
+func (s *ArticleService) DeleteArticle(id string) error {
+	return s.store.Update(func(tx *store.Tx) error {
+		existing, ok := tx.GetArticle(id)
+		if !ok {
+			return fmt.Errorf("%w: %s", store.ErrArticleNotFound, id)
+		}
+		if err := tx.DeleteArticle(id); err != nil {
+			return err
+		}
+		// Kategori ikut dikirim hanya untuk routing key.
+		payload := model.DocumentNews{ID: existing.ID, Category: existing.Category}
+		_, err := tx.AppendEvent(model.NewNewsEvent(model.EventDeleted, payload))
+		return err
+	})
+}
+
+// applyInput menyalin field dari input ke doc; ID dan timestamp milik service tidak berubah.
+func applyInput(doc model.DocumentNews, input model.ArticleInput) model.DocumentNews {
+	doc.Title = input.Title
+	doc.Content = input.Content
+	doc.Author = input.Author
+	doc.Tags = input.Tags
+	if doc.Tags == nil {
+		doc.Tags = []string{}
+	}
+	doc.Category = input.Category
+	if input.PublishedAt != nil {
+		doc.PublishedAt = input.PublishedAt.UTC()
+	}
+	return doc
+}
+
+// patchFields memeriksa key patch dan mengembalikannya dalam urutan model.ArticleFields.
+func patchFields(patch map[string]json.RawMessage) ([]string, error) {
+	var problems []string
+	for key := range patch {
+		switch {
+		case slices.Contains(model.ArticleFields, key):
+		case key == "id" || key == "created_at" || key == "updated_at":
+			problems = append(problems, fmt.Sprintf("field '%s' is set by the service and cannot be changed", key))
+		default:
+			problems = append(problems, fmt.Sprintf("unknown field '%s'", key))
+		}
+	}
+	if len(patch) == 0 {
+		problems = append(problems, "patch must contain at least one field")
+	}
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		return nil, &model.ArticleValidationError{Problems: problems}
+	}
+
+	var fields []string
+	for _, field := range model.ArticleFields {
+		if _, ok := patch[field]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// applyPatch men-decode setiap field patch ke input. Key sudah diperiksa patchFields.
+func applyPatch(input model.ArticleInput, patch map[string]json.RawMessage) (model.ArticleInput, error) {
+	var problems []string
+	for _, field := range model.ArticleFields {
+		raw, ok := patch[field]
+		if !ok {
+			continue
+		}
+
+		var target interface{}
+		switch field {
+		case "title":
+			target = &input.Title
+		case "content":
+			target = &input.Content
+		case "author":
+			target = &input.Author
+		case "tags":
+			target = &input.Tags
+		case "category":
+			target = &input.Category
+		case "published_at":
+			input.PublishedAt = nil
+			target = &input.PublishedAt
+		}
+		if err := json.Unmarshal(raw, target); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid value for '%s': %v", field, err))
+			continue
+		}
+		if field == "published_at" && input.PublishedAt == nil {
+			problems = append(problems, "published_at cannot be null")
+		}
+	}
+	if len(problems) > 0 {
+		return input, &model.ArticleValidationError{Problems: problems}
+	}
+	return input, nil
+}
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"news_service/pkg/model"
+	"os"
+	"path/filepath"
+)
+
+// FileStore menyimpan state dalam satu file JSON. Setiap transaksi menulis ulang
+// seluruh file lewat file sementara + fsync + rename, sehingga perubahan artikel dan
+// event outbox-nya tersimpan bersama atau tidak sama sekali. Cocok untuk volume kecil.
+type FileStore struct {
+	core
+	path string
+}
+
+// OpenFileStore memuat store dari path, atau membuat store kosong jika file belum ada.
+func OpenFileStore(path string) (*FileStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create news store directory: %w", err)
+		}
+	}
+
+	s := &FileStore{core: core{state: newState()}, path: path}
+	s.persist = s.write
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read news store: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, fmt.Errorf("failed to parse news store %s: %w", path, err)
+	}
+	if s.state.Articles == nil {
+		s.state.Articles = make(map[string]model.DocumentNews)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Backend() string { return BackendFile }
+
+// write menulis state ke file sementara, fsync, lalu rename ke path tujuan.
+func (s *FileStore) write(st *state) error {
+	data, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("failed to encode news store: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary news store file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // Tidak berpengaruh setelah rename berhasil
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write news store: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync news store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close news store file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		return fmt.Errorf("failed to replace news store file: %w", err)
+	}
+	syncDir(filepath.Dir(s.path))
+	return nil
+}
+
+// syncDir memastikan rename tercatat di direktori; kegagalan diabaikan karena tidak
+// semua sistem file mendukung fsync pada direktori.
+func syncDir(dir string) {
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+}
@@ -0,0 +1,337 @@
+// Package store menyimpan artikel beserta outbox event-nya. Setiap perubahan artikel
+// dan event yang menyertainya ditulis dalam satu transaksi, sehingga event tidak
+// mungkin hilang walaupun proses berhenti sebelum event dipublikasikan.
+package store
+
+import (
+	"errors"
+	"fmt"
+	"news_service/pkg/model"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Backend store yang didukung.
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+)
+
+// ErrArticleNotFound dikembalikan jika artikel dengan ID tertentu tidak ada.
+var ErrArticleNotFound = errors.New("article not found")
+
+// ErrParkedEntryNotFound dikembalikan jika entri parked dengan seq tertentu tidak ada.
+var ErrParkedEntryNotFound = errors.New("parked outbox entry not found")
+
+// Store adalah repository artikel dengan outbox transaksional.
+type Store interface {
+	// Update menjalankan fn dalam satu transaksi; jika fn mengembalikan error atau
+	// perubahan gagal disimpan, tidak ada perubahan yang diterapkan.
+	Update(fn func(tx *Tx) error) error
+	GetArticle(id string) (model.DocumentNews, bool)
+	// ListArticles mengembalikan semua artikel, diurutkan berdasarkan ID.
+	ListArticles() []model.DocumentNews
+
+	// PendingOutbox mengembalikan entri tertua dari setiap artikel di seluruh outbox,
+	// maksimal limit artikel, dengan urutan outbox. Entri berikutnya milik artikel yang
+	// sama baru muncul setelah entri sebelumnya terkirim atau di-park.
+	PendingOutbox(limit int) []OutboxEntry
+	// MarkSent menghapus entri outbox yang sudah dikonfirmasi broker.
+	MarkSent(seqs ...int64) error
+	// RecordFailure mencatat percobaan publish yang gagal untuk sebuah entri outbox.
+	RecordFailure(seq int64, cause error) error
+	// ParkOutbox mencatat kegagalan terakhir lalu memindahkan entri dari outbox ke
+	// daftar parked, sehingga tidak lagi dicoba relay dan tidak menahan entri lain.
+	ParkOutbox(seq int64, cause error) error
+	// ParkedOutbox mengembalikan entri yang di-park, dari yang terlama.
+	ParkedOutbox() []OutboxEntry
+	// RequeueParked mengembalikan entri parked ke akhir outbox dengan seq baru dan
+	// hitungan percobaan dari nol.
+	RequeueParked(seq int64) (OutboxEntry, error)
+	// DiscardParked menghapus entri parked tanpa mempublikasikannya.
+	DiscardParked(seq int64) error
+	OutboxLen() int
+	// OnOutboxAppend mendaftarkan fn yang dipanggil setelah transaksi menambah event,
+	// mis. untuk membangunkan relay.
+	OnOutboxAppend(fn func())
+
+	Backend() string
+}
+
+// NewStore membuat store sesuai backend: "memory" atau "file" (path dipakai untuk file).
+func NewStore(backend, path string) (Store, error) {
+	switch backend {
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case BackendFile:
+		return OpenFileStore(path)
+	default:
+		return nil, fmt.Errorf("unknown news store backend '%s'", backend)
+	}
+}
+
+// OutboxEntry adalah event yang menunggu dipublikasikan oleh relay.
+type OutboxEntry struct {
+	Seq       int64           `json:"seq"`
+	Event     model.NewsEvent `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Attempts  int             `json:"attempts"`
+	LastError string          `json:"last_error,omitempty"`
+	// ParkedAt diisi saat entri dipindahkan ke daftar parked.
+	ParkedAt *time.Time `json:"parked_at,omitempty"`
+}
+
+// state adalah seluruh isi store; FileStore menyimpannya sebagai JSON.
+type state struct {
+	Articles    map[string]model.DocumentNews `json:"articles"`
+	Outbox      []OutboxEntry                 `json:"outbox"`
+	Parked      []OutboxEntry                 `json:"parked,omitempty"`
+	NextSeq     int64                         `json:"next_seq"`
+	LastVersion int64                         `json:"last_version"`
+}
+
+func newState() state {
+	return state{Articles: make(map[string]model.DocumentNews), NextSeq: 1}
+}
+
+// clone membuat salinan state agar transaksi yang gagal tidak mengubah state aktif.
+func (st state) clone() state {
+	articles := make(map[string]model.DocumentNews, len(st.Articles))
+	for id, doc := range st.Articles {
+		articles[id] = doc
+	}
+	return state{
+		Articles:    articles,
+		Outbox:      slices.Clone(st.Outbox),
+		Parked:      slices.Clone(st.Parked),
+		NextSeq:     st.NextSeq,
+		LastVersion: st.LastVersion,
+	}
+}
+
+// Tx adalah transaksi terhadap salinan state. Perubahan baru terlihat dan tersimpan
+// setelah fungsi transaksi selesai tanpa error.
+type Tx struct {
+	state    *state
+	appended bool
+}
+
+// GetArticle mengembalikan artikel dalam transaksi.
+func (tx *Tx) GetArticle(id string) (model.DocumentNews, bool) {
+	doc, ok := tx.state.Articles[id]
+	return doc, ok
+}
+
+// PutArticle menyimpan atau mengganti artikel.
+func (tx *Tx) PutArticle(doc model.DocumentNews) {
+	tx.state.Articles[doc.ID] = doc
+}
+
+// DeleteArticle menghapus artikel; mengembalikan ErrArticleNotFound jika tidak ada.
+func (tx *Tx) DeleteArticle(id string) error {
+	if _, ok := tx.state.Articles[id]; !ok {
+		return fmt.Errorf("%w: %s", ErrArticleNotFound, id)
+	}
+	delete(tx.state.Articles, id)
+	return nil
+}
+
+// AppendEvent memvalidasi event lalu menambahkannya ke outbox. Version dinaikkan jika
+// perlu agar selalu lebih besar dari event mana pun yang pernah disimpan, termasuk
+// sebelum restart. Event yang disimpan (dengan Version final) dikembalikan.
+func (tx *Tx) AppendEvent(event model.NewsEvent) (model.NewsEvent, error) {
+	if err := event.Validate(); err != nil {
+		return model.NewsEvent{}, err
+	}
+	if event.Version <= tx.state.LastVersion {
+		event.Version = tx.state.LastVersion + 1
+	}
+	tx.state.LastVersion = event.Version
+
+	tx.state.Outbox = append(tx.state.Outbox, OutboxEntry{
+		Seq:       tx.state.NextSeq,
+		Event:     event,
+		CreatedAt: time.Now(),
+	})
+	tx.state.NextSeq++
+	tx.appended = true
+	return event, nil
+}
+
+// core berisi state dan logika transaksi yang dipakai bersama oleh kedua implementasi
+// store. persist dipanggil sebelum state baru diterapkan; nil berarti hanya di memori.
+type core struct {
+	mu       sync.RWMutex
+	state    state
+	persist  func(st *state) error
+	onAppend func()
+}
+
+func (c *core) Update(fn func(tx *Tx) error) error {
+	c.mu.Lock()
+	next := c.state.clone()
+	tx := &Tx{state: &next}
+	if err := fn(tx); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	if c.persist != nil {
+		if err := c.persist(&next); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	c.state = next
+	onAppend := c.onAppend
+	c.mu.Unlock()
+
+	if tx.appended && onAppend != nil {
+		onAppend()
+	}
+	return nil
+}
+
+func (c *core) GetArticle(id string) (model.DocumentNews, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	doc, ok := c.state.Articles[id]
+	return doc, ok
+}
+
+func (c *core) ListArticles() []model.DocumentNews {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	docs := make([]model.DocumentNews, 0, len(c.state.Articles))
+	for _, doc := range c.state.Articles {
+		docs = append(docs, doc)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].ID < docs[j].ID })
+	return docs
+}
+
+func (c *core) PendingOutbox(limit int) []OutboxEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var entries []OutboxEntry
+	seen := make(map[string]bool)
+	for _, entry := range c.state.Outbox {
+		if limit > 0 && len(entries) >= limit {
+			break
+		}
+		if id := entry.Event.Payload.ID; !seen[id] {
+			seen[id] = true
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (c *core) MarkSent(seqs ...int64) error {
+	sent := make(map[int64]bool, len(seqs))
+	for _, seq := range seqs {
+		sent[seq] = true
+	}
+	return c.Update(func(tx *Tx) error {
+		remaining := tx.state.Outbox[:0]
+		for _, entry := range tx.state.Outbox {
+			if !sent[entry.Seq] {
+				remaining = append(remaining, entry)
+			}
+		}
+		tx.state.Outbox = remaining
+		return nil
+	})
+}
+
+func (c *core) RecordFailure(seq int64, cause error) error {
+	return c.Update(func(tx *Tx) error {
+		for i := range tx.state.Outbox {
+			if tx.state.Outbox[i].Seq == seq {
+				tx.state.Outbox[i].Attempts++
+				tx.state.Outbox[i].LastError = cause.Error()
+			}
+		}
+		return nil
+	})
+}
+
+func (c *core) ParkOutbox(seq int64, cause error) error {
+	return c.Update(func(tx *Tx) error {
+		i := slices.IndexFunc(tx.state.Outbox, func(entry OutboxEntry) bool { return entry.Seq == seq })
+		if i < 0 {
+			return nil // Sudah terkirim atau di-park
+		}
+		entry := tx.state.Outbox[i]
+		entry.Attempts++
+		entry.LastError = cause.Error()
+		now := time.Now()
+		entry.ParkedAt = &now
+		tx.state.Outbox = slices.Delete(tx.state.Outbox, i, i+1)
+		tx.state.Parked = append(tx.state.Parked, entry)
+		return nil
+	})
+}
+
+func (c *core) ParkedOutbox() []OutboxEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clone(c.state.Parked)
+}
+
+func (c *core) RequeueParked(seq int64) (OutboxEntry, error) {
+	var requeued OutboxEntry
+	err := c.Update(func(tx *Tx) error {
+		i := slices.IndexFunc(tx.state.Parked, func(entry OutboxEntry) bool { return entry.Seq == seq })
+		if i < 0 {
+			return fmt.Errorf("%w: %d", ErrParkedEntryNotFound, seq)
+		}
+		requeued = OutboxEntry{
+			Seq:       tx.state.NextSeq,
+			Event:     tx.state.Parked[i].Event,
+			CreatedAt: time.Now(),
+		}
+		tx.state.NextSeq++
+		tx.state.Parked = slices.Delete(tx.state.Parked, i, i+1)
+		tx.state.Outbox = append(tx.state.Outbox, requeued)
+		tx.appended = true
+		return nil
+	})
+	return requeued, err
+}
+
+func (c *core) DiscardParked(seq int64) error {
+	return c.Update(func(tx *Tx) error {
+		i := slices.IndexFunc(tx.state.Parked, func(entry OutboxEntry) bool { return entry.Seq == seq })
+		if i < 0 {
+			return fmt.Errorf("%w: %d", ErrParkedEntryNotFound, seq)
+		}
+		tx.state.Parked = slices.Delete(tx.state.Parked, i, i+1)
+		return nil
+	})
+}
+
+func (c *core) OutboxLen() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.state.Outbox)
+}
+
+func (c *core) OnOutboxAppend(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onAppend = fn
+}
+
+// MemoryStore menyimpan artikel dan outbox di memori; isinya (termasuk event yang
+// belum terkirim) hilang saat proses berhenti, sehingga hanya cocok untuk development.
+type MemoryStore struct {
+	core
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{core: core{state: newState()}}
+}
+
+func (s *MemoryStore) Backend() string { return BackendMemory }
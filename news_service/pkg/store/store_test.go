@@ -0,0 +1,153 @@
+package store
+
+import (
+	"errors"
+	"news_service/pkg/model"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// appendEvents menambahkan satu event DELETED per ID artikel, berurutan.
+func appendEvents(t *testing.T, s Store, ids ...string) []int64 {
+	t.Helper()
+	var seqs []int64
+	for _, id := range ids {
+		err := s.Update(func(tx *Tx) error {
+			if _, err := tx.AppendEvent(model.NewNewsEvent(model.EventDeleted, model.DocumentNews{ID: id})); err != nil {
+				return err
+			}
+			seqs = append(seqs, tx.state.NextSeq-1)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to append event for %s: %v", id, err)
+		}
+	}
+	return seqs
+}
+
+func pendingSeqs(s Store, limit int) []int64 {
+	var seqs []int64
+	for _, entry := range s.PendingOutbox(limit) {
+		seqs = append(seqs, entry.Seq)
+	}
+	return seqs
+}
+
+func TestPendingOutboxReturnsOldestEntryPerArticle(t *testing.T) {
+	tests := []struct {
+		name  string
+		ids   []string
+		limit int
+		want  []int64
+	}{
+		{"one entry per article", []string{"a", "b", "c"}, 10, []int64{1, 2, 3}},
+		{"later entries of an article wait", []string{"a", "a", "b", "a", "c"}, 10, []int64{1, 3, 5}},
+		{"heads are found beyond the first rows", []string{"a", "a", "a", "a", "b"}, 2, []int64{1, 5}},
+		{"limit counts articles", []string{"a", "b", "c"}, 2, []int64{1, 2}},
+		{"no limit", []string{"a", "b", "a"}, 0, []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			appendEvents(t, s, tt.ids...)
+			if got := pendingSeqs(s, tt.limit); !slices.Equal(got, tt.want) {
+				t.Errorf("PendingOutbox(%d) seqs = %v, want %v", tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParkOutboxReleasesNextEntryOfArticle(t *testing.T) {
+	s := NewMemoryStore()
+	seqs := appendEvents(t, s, "a", "a", "b")
+
+	if err := s.RecordFailure(seqs[0], errors.New("returned")); err != nil {
+		t.Fatalf("RecordFailure returned error: %v", err)
+	}
+	if err := s.ParkOutbox(seqs[0], errors.New("returned again")); err != nil {
+		t.Fatalf("ParkOutbox returned error: %v", err)
+	}
+
+	if got, want := pendingSeqs(s, 10), []int64{seqs[1], seqs[2]}; !slices.Equal(got, want) {
+		t.Errorf("PendingOutbox seqs after park = %v, want %v", got, want)
+	}
+	if got := s.OutboxLen(); got != 2 {
+		t.Errorf("OutboxLen() = %d, want 2", got)
+	}
+
+	parked := s.ParkedOutbox()
+	if len(parked) != 1 {
+		t.Fatalf("ParkedOutbox() returned %d entries, want 1", len(parked))
+	}
+	if p := parked[0]; p.Seq != seqs[0] || p.Attempts != 2 || p.LastError != "returned again" || p.ParkedAt == nil {
+		t.Errorf("parked entry = %+v, want seq %d with 2 attempts, the last error and parked_at", p, seqs[0])
+	}
+}
+
+func TestRequeueAndDiscardParked(t *testing.T) {
+	s := NewMemoryStore()
+	seqs := appendEvents(t, s, "a", "b")
+	for _, seq := range seqs {
+		if err := s.ParkOutbox(seq, errors.New("returned")); err != nil {
+			t.Fatalf("ParkOutbox returned error: %v", err)
+		}
+	}
+
+	woken := false
+	s.OnOutboxAppend(func() { woken = true })
+	requeued, err := s.RequeueParked(seqs[0])
+	if err != nil {
+		t.Fatalf("RequeueParked returned error: %v", err)
+	}
+	if requeued.Seq <= seqs[1] || requeued.Attempts != 0 || requeued.ParkedAt != nil {
+		t.Errorf("requeued entry = %+v, want a new seq with attempts reset", requeued)
+	}
+	if !woken {
+		t.Error("RequeueParked did not wake the relay")
+	}
+	if got := pendingSeqs(s, 10); !slices.Equal(got, []int64{requeued.Seq}) {
+		t.Errorf("PendingOutbox seqs = %v, want [%d]", got, requeued.Seq)
+	}
+
+	if err := s.DiscardParked(seqs[1]); err != nil {
+		t.Fatalf("DiscardParked returned error: %v", err)
+	}
+	if got := s.ParkedOutbox(); len(got) != 0 {
+		t.Errorf("ParkedOutbox() = %+v, want empty", got)
+	}
+
+	for name, err := range map[string]error{
+		"requeue": func() error { _, err := s.RequeueParked(seqs[0]); return err }(),
+		"discard": s.DiscardParked(seqs[1]),
+	} {
+		if !errors.Is(err, ErrParkedEntryNotFound) {
+			t.Errorf("%s of a missing entry returned %v, want ErrParkedEntryNotFound", name, err)
+		}
+	}
+}
+
+func TestFileStorePersistsParkedEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "news_store.json")
+	s, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore returned error: %v", err)
+	}
+	seqs := appendEvents(t, s, "a", "b")
+	if err := s.ParkOutbox(seqs[0], errors.New("returned")); err != nil {
+		t.Fatalf("ParkOutbox returned error: %v", err)
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("OpenFileStore (reopen) returned error: %v", err)
+	}
+	if got := reopened.ParkedOutbox(); len(got) != 1 || got[0].Seq != seqs[0] {
+		t.Errorf("ParkedOutbox() after reopen = %+v, want entry %d", got, seqs[0])
+	}
+	if got := pendingSeqs(reopened, 10); !slices.Equal(got, []int64{seqs[1]}) {
+		t.Errorf("PendingOutbox seqs after reopen = %v, want [%d]", got, seqs[1])
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff menghitung jeda exponential dengan full jitter untuk percobaan koneksi ulang.
+type Backoff struct {
+	Min     time.Duration
+	Max     time.Duration
+	attempt int
+}
+
+// Next mengembalikan jeda untuk percobaan berikutnya: acak antara Min dan
+// min(Max, Min*2^attempt).
+func (b *Backoff) Next() time.Duration {
+	ceiling := b.Min << b.attempt
+	if ceiling <= 0 || ceiling > b.Max {
+		ceiling = b.Max
+	} else {
+		b.attempt++
+	}
+	if ceiling <= b.Min {
+		return b.Min
+	}
+	return b.Min + rand.N(ceiling-b.Min)
+}
+
+// Reset dipanggil setelah koneksi berhasil agar jeda kembali ke nilai minimum.
+func (b *Backoff) Reset() {
+	b.attempt = 0
+}
@@ -0,0 +1,40 @@
+package util
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+type APIResponse struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   interface{} `json:"error,omitempty"`
+}
+
+func SendSuccessResponse(w http.ResponseWriter, statusCode int, message string, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	response := APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding success response: %v", err)
+	}
+}
+
+func SendErrorResponse(w http.ResponseWriter, statusCode int, message string, errDetail interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	response := APIResponse{
+		Success: false,
+		Message: message,
+		Error:   errDetail,
+	}
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		log.Printf("Error encoding error response: %v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"os/signal"
 	"search_service/pkg/config"
 	"search_service/pkg/consumer"
+	"search_service/pkg/dedupe"
 	"search_service/pkg/handler"
 	"search_service/pkg/repository"
 	"search_service/pkg/service"
@@ -23,6 +24,7 @@ type Application struct {
 	ESRepo      *repository.ElasticSearchRepository
 	NewsService *service.NewsService
 	Consumer    *consumer.RabbitMQConsumer
+	Dedupe      dedupe.Store
 }
 
 func NewApplication(cfg *config.AppConfig, esRepo *repository.ElasticSearchRepository) *Application {
@@ -32,36 +34,64 @@ func NewApplication(cfg *config.AppConfig, esRepo *repository.ElasticSearchRepos
 		ESRepo: esRepo,
 	}
 	adminHandler := handler.NewAdminHandler(app.ESRepo)
-	newsService := service.NewNewsService(app.ESRepo)
+	newsService := service.NewNewsService(app.ESRepo, cfg)
+	if err := newsService.EnsureIndex(context.Background()); err != nil {
+		log.Fatalf("Failed to ensure news index: %v", err)
+	}
 	app.NewsService = newsService
 	newsHandler := handler.NewNewsHandler(newsService)
-	app.setupRoutes(adminHandler, newsHandler)
 
-	rmqConsumer, err := consumer.NewRabbitMQConsumer(cfg, newsService)
+	dedupeStore, err := dedupe.NewStore(cfg.DedupeStore, cfg.DedupeFilePath, cfg.DedupeRetention)
+	if err != nil {
+		log.Fatalf("Failed to initialize dedupe store: %v", err)
+	}
+	app.Dedupe = dedupeStore
+	log.Printf("Dedupe store '%s' ready with %d tracked events.", dedupeStore.Backend(), dedupeStore.Len())
+
+	rmqConsumer, err := consumer.NewRabbitMQConsumer(cfg, newsService, dedupeStore)
 	if err != nil {
 		log.Fatalf("Failed to initialize RabbitMQ consumer: %v", err)
 	}
 	app.Consumer = rmqConsumer
+	dlqHandler := handler.NewDLQHandler(consumer.NewDeadLetterManager(rmqConsumer))
+	healthHandler := handler.NewHealthHandler(app.ESRepo, rmqConsumer)
+	consumerHandler := handler.NewConsumerHandler(rmqConsumer)
+
+	app.setupRoutes(adminHandler, newsHandler, dlqHandler, healthHandler, consumerHandler)
 
 	return app
 }
 
 func (a *Application) setupRoutes(
 	adminHandler *handler.AdminHandler,
-	newsHandler *handler.NewsHandler) {
+	newsHandler *handler.NewsHandler,
+	dlqHandler *handler.DLQHandler,
+	healthHandler *handler.HealthHandler,
+	consumerHandler *handler.ConsumerHandler) {
 	a.Router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "Welcome to Go Event-Driven Search Service!")
 	}).Methods("GET")
 
 	adminRouter := a.Router.PathPrefix("/admin").Subrouter()
 	adminRouter.HandleFunc("/info", adminHandler.GetElasticsearchInfo).Methods("GET")
+	adminRouter.HandleFunc("/health", healthHandler.GetHealth).Methods("GET")
+	adminRouter.HandleFunc("/consumer/stats", consumerHandler.GetStats).Methods("GET")
 	adminRouter.HandleFunc("/indices/info/{name}", adminHandler.GetExistElasticIndex).Methods("GET")
 	adminRouter.HandleFunc("/indices/{name}", adminHandler.CreateIndex).Methods("POST")
 	adminRouter.HandleFunc("/indices/{name}", adminHandler.DeleteIndex).Methods("DELETE")
 
+	adminRouter.HandleFunc("/dlq", dlqHandler.ListMessages).Methods("GET")
+	adminRouter.HandleFunc("/dlq", dlqHandler.PurgeMessages).Methods("DELETE")
+	adminRouter.HandleFunc("/dlq/replay", dlqHandler.ReplayAll).Methods("POST")
+	adminRouter.HandleFunc("/dlq/{id}", dlqHandler.GetMessage).Methods("GET")
+	adminRouter.HandleFunc("/dlq/{id}", dlqHandler.DeleteMessage).Methods("DELETE")
+	adminRouter.HandleFunc("/dlq/{id}/replay", dlqHandler.ReplayMessage).Methods("POST")
+
 	newsRouter := a.Router.PathPrefix("/news").Subrouter()
 	newsRouter.HandleFunc("", newsHandler.SearchNews).Methods("GET")
+	newsRouter.HandleFunc("/suggest", newsHandler.SuggestNews).Methods("GET")
 	newsRouter.HandleFunc("/{id}", newsHandler.GetNewsByID).Methods("GET")
+	newsRouter.HandleFunc("/{id}/related", newsHandler.RelatedNews).Methods("GET")
 
 }
 
@@ -83,4 +113,7 @@ func (a *Application) StartApplication() {
 	log.Println("Shutting down application gracefully...")
 	a.Consumer.Close()
 	log.Println("RabbitMQ Consumer closed during shutdown.")
+	if err := a.Dedupe.Close(); err != nil {
+		log.Printf("Error closing dedupe store: %v", err)
+	}
 }
@@ -0,0 +1,299 @@
+package consumer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"search_service/pkg/eventschema"
+	"search_service/pkg/util"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// HeaderReplayedAt ditambahkan pada pesan yang diputar ulang dari DLQ.
+const HeaderReplayedAt = "x-replayed-at"
+
+// maxDeadLetterScan membatasi jumlah pesan yang dibaca saat mencari satu pesan di DLQ.
+const maxDeadLetterScan = 10000
+
+// ErrDeadLetterNotFound dikembalikan jika pesan dengan ID tertentu tidak ada di DLQ.
+var ErrDeadLetterNotFound = errors.New("dead-letter message not found")
+
+// DeadLetterMessage adalah ringkasan pesan yang terparkir di DLQ.
+type DeadLetterMessage struct {
+	ID             string                 `json:"id"`
+	Reason         string                 `json:"reason"`
+	LastError      string                 `json:"last_error,omitempty"`
+	Attempts       int                    `json:"attempts"`
+	DeadLetteredAt string                 `json:"dead_lettered_at,omitempty"`
+	EventType      string                 `json:"event_type,omitempty"`
+	DocumentID     string                 `json:"document_id,omitempty"`
+	ContentType    string                 `json:"content_type,omitempty"`
+	Headers        map[string]interface{} `json:"headers,omitempty"`
+	Body           json.RawMessage        `json:"body,omitempty"`
+	RawBody        string                 `json:"raw_body,omitempty"`
+}
+
+// DeadLetterManager membaca, memutar ulang dan menghapus pesan di DLQ tanpa RabbitMQ UI.
+// AMQP tidak mendukung membaca queue tanpa mengambil pesannya, sehingga pesan dibaca
+// dengan basic.get tanpa ack; pesan yang tidak diproses kembali ke queue saat channel ditutup.
+type DeadLetterManager struct {
+	Consumer *RabbitMQConsumer
+}
+
+func NewDeadLetterManager(c *RabbitMQConsumer) *DeadLetterManager {
+	return &DeadLetterManager{Consumer: c}
+}
+
+// List mengembalikan hingga limit pesan pertama di DLQ beserta jumlah total pesan.
+func (m *DeadLetterManager) List(limit int) ([]DeadLetterMessage, int, error) {
+	ch, err := m.Consumer.OpenChannel()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer ch.Close()
+
+	queue, err := ch.QueueInspect(m.Consumer.deadLetterQueueName())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to inspect dead-letter queue: %w", err)
+	}
+
+	messages := make([]DeadLetterMessage, 0, min(limit, queue.Messages))
+	for len(messages) < limit {
+		d, ok, err := ch.Get(m.Consumer.deadLetterQueueName(), false)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		messages = append(messages, toDeadLetterMessage(d, false))
+	}
+	return messages, queue.Messages, nil
+}
+
+// Get mengembalikan satu pesan DLQ lengkap dengan body dan header-nya.
+func (m *DeadLetterManager) Get(id string) (*DeadLetterMessage, error) {
+	var found *DeadLetterMessage
+	err := m.withMessage(id, func(ch *amqp091.Channel, d amqp091.Delivery) error {
+		msg := toDeadLetterMessage(d, true)
+		found = &msg
+		return nil
+	})
+	return found, err
+}
+
+// Replay mengirim ulang satu pesan ke queue utama. Jika patch tidak kosong, patch
+// (JSON Merge Patch) diterapkan pada payload event sebelum dikirim.
+func (m *DeadLetterManager) Replay(ctx context.Context, id string, patch json.RawMessage) error {
+	return m.withMessage(id, func(ch *amqp091.Channel, d amqp091.Delivery) error {
+		if err := ch.Confirm(false); err != nil {
+			return fmt.Errorf("failed to enable publisher confirms: %w", err)
+		}
+		body := d.Body
+		if len(patch) > 0 {
+			patched, err := patchEventPayload(body, patch)
+			if err != nil {
+				return err
+			}
+			body = patched
+		}
+		if err := replayDelivery(ctx, ch, m.Consumer.queueName(), d, body); err != nil {
+			return err
+		}
+		log.Printf("Dead-letter message %s replayed to queue '%s'.", id, m.Consumer.queueName())
+		return d.Ack(false)
+	})
+}
+
+// ReplayAll mengirim ulang semua pesan yang ada di DLQ saat ini ke queue utama.
+func (m *DeadLetterManager) ReplayAll(ctx context.Context) (int, error) {
+	ch, err := m.Consumer.OpenChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	if err := ch.Confirm(false); err != nil {
+		return 0, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	queue, err := ch.QueueInspect(m.Consumer.deadLetterQueueName())
+	if err != nil {
+		return 0, fmt.Errorf("failed to inspect dead-letter queue: %w", err)
+	}
+
+	// Batasi pada jumlah pesan awal agar pesan yang baru gagal tidak ikut terputar berulang.
+	replayed := 0
+	for replayed < queue.Messages {
+		d, ok, err := ch.Get(m.Consumer.deadLetterQueueName(), false)
+		if err != nil {
+			return replayed, fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		if err := replayDelivery(ctx, ch, m.Consumer.queueName(), d, d.Body); err != nil {
+			return replayed, err
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, fmt.Errorf("failed to acknowledge replayed message: %w", err)
+		}
+		replayed++
+	}
+	log.Printf("Replayed %d dead-letter messages to queue '%s'.", replayed, m.Consumer.queueName())
+	return replayed, nil
+}
+
+// Delete menghapus satu pesan dari DLQ secara permanen.
+func (m *DeadLetterManager) Delete(id string) error {
+	return m.withMessage(id, func(ch *amqp091.Channel, d amqp091.Delivery) error {
+		log.Printf("Dead-letter message %s purged.", id)
+		return d.Ack(false)
+	})
+}
+
+// Purge menghapus semua pesan di DLQ dan mengembalikan jumlah pesan yang dihapus.
+func (m *DeadLetterManager) Purge() (int, error) {
+	ch, err := m.Consumer.OpenChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	count, err := ch.QueuePurge(m.Consumer.deadLetterQueueName(), false)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge dead-letter queue: %w", err)
+	}
+	log.Printf("Purged %d messages from dead-letter queue '%s'.", count, m.Consumer.deadLetterQueueName())
+	return count, nil
+}
+
+// withMessage mencari pesan dengan ID tertentu di DLQ dan menjalankan fn terhadapnya.
+// Pesan lain yang terbaca tidak di-ack sehingga kembali ke DLQ saat channel ditutup.
+func (m *DeadLetterManager) withMessage(id string, fn func(ch *amqp091.Channel, d amqp091.Delivery) error) error {
+	ch, err := m.Consumer.OpenChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for i := 0; i < maxDeadLetterScan; i++ {
+		d, ok, err := ch.Get(m.Consumer.deadLetterQueueName(), false)
+		if err != nil {
+			return fmt.Errorf("failed to read dead-letter queue: %w", err)
+		}
+		if !ok {
+			break
+		}
+		if d.MessageId == id {
+			return fn(ch, d)
+		}
+	}
+	return ErrDeadLetterNotFound
+}
+
+// replayDelivery mengirim salinan pesan DLQ ke queue utama dan menunggu konfirmasi
+// broker; pesan DLQ hanya boleh di-ack setelah fungsi ini berhasil. ch harus dalam
+// confirm mode.
+func replayDelivery(ctx context.Context, ch *amqp091.Channel, queue string, d amqp091.Delivery, body []byte) error {
+	headers := copyHeaders(d.Headers)
+	for _, key := range []string{HeaderRetryCount, HeaderLastError, HeaderDeadLetterWhy, HeaderDeadLetterAt, HeaderOriginalQueue, HeaderFirstFailureAt} {
+		delete(headers, key)
+	}
+	headers[HeaderReplayedAt] = time.Now().UTC().Format(time.RFC3339)
+
+	err := publishConfirmed(ctx, ch, queue, amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Type:         d.Type,
+		Body:         body,
+		DeliveryMode: amqp091.Persistent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replay message %s: %w", d.MessageId, err)
+	}
+	return nil
+}
+
+// patchEventPayload menerapkan merge patch pada field "payload" dari event. Untuk
+// CloudEvents mode structured, payload berada di dalam field "data".
+func patchEventPayload(body []byte, patch json.RawMessage) ([]byte, error) {
+	var event map[string]json.RawMessage
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("cannot patch message that is not a JSON object: %w", err)
+	}
+	if _, structured := event["specversion"]; structured {
+		data, err := patchEventPayload(event["data"], patch)
+		if err != nil {
+			return nil, err
+		}
+		event["data"] = data
+		return json.Marshal(event)
+	}
+
+	payload, err := util.ApplyMergePatch(event["payload"], patch)
+	if err != nil {
+		return nil, err
+	}
+	event["payload"] = payload
+	return json.Marshal(event)
+}
+
+func toDeadLetterMessage(d amqp091.Delivery, withBody bool) DeadLetterMessage {
+	msg := DeadLetterMessage{
+		ID:          d.MessageId,
+		Attempts:    retryCount(d.Headers) + 1,
+		ContentType: d.ContentType,
+	}
+	msg.Reason, _ = d.Headers[HeaderDeadLetterWhy].(string)
+	msg.LastError, _ = d.Headers[HeaderLastError].(string)
+	msg.DeadLetteredAt, _ = d.Headers[HeaderDeadLetterAt].(string)
+
+	var summary struct {
+		Type    string `json:"type"`
+		Subject string `json:"subject"`
+		Payload struct {
+			ID string `json:"id"`
+		} `json:"payload"`
+	}
+	if json.Unmarshal(d.Body, &summary) == nil {
+		msg.EventType = summary.Type
+		msg.DocumentID = summary.Payload.ID
+		if msg.DocumentID == "" {
+			msg.DocumentID = summary.Subject // CloudEvents mode structured
+		}
+	}
+	// CloudEvents mode binary menyimpan atribut di header.
+	if ceType, ok := d.Headers[eventschema.CloudEventsHeaderPrefix+"type"].(string); ok {
+		msg.EventType = ceType
+	}
+	if subject, ok := d.Headers[eventschema.CloudEventsHeaderPrefix+"subject"].(string); ok && msg.DocumentID == "" {
+		msg.DocumentID = subject
+	}
+
+	if withBody {
+		msg.Headers = d.Headers
+		if json.Valid(d.Body) {
+			msg.Body = d.Body
+		} else {
+			msg.RawBody = string(d.Body)
+		}
+	}
+	return msg
+}
+
+// newMessageID membuat ID acak untuk pesan yang dipublikasikan tanpa MessageId.
+func newMessageID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,70 @@
+package consumer
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPatchEventPayload(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "legacy event",
+			body:  `{"event_id":"e1","type":"CREATED","payload":{"id":"a1","title":"Lama","category":"politik"}}`,
+			patch: `{"title":"Baru","category":null}`,
+			want:  `{"event_id":"e1","type":"CREATED","payload":{"id":"a1","title":"Baru"}}`,
+		},
+		{
+			name:  "legacy event without payload",
+			body:  `{"event_id":"e1","type":"DELETED"}`,
+			patch: `{"id":"a1"}`,
+			want:  `{"event_id":"e1","type":"DELETED","payload":{"id":"a1"}}`,
+		},
+		{
+			name:  "binary cloudevent body is the data",
+			body:  `{"schema_version":2,"version":3,"payload":{"id":"a1","tags":["x"]}}`,
+			patch: `{"tags":["x","y"]}`,
+			want:  `{"schema_version":2,"version":3,"payload":{"id":"a1","tags":["x","y"]}}`,
+		},
+		{
+			name: "structured cloudevent patches payload inside data",
+			body: `{"specversion":"1.0","id":"e1","type":"com.news.article.updated","source":"news_service",` +
+				`"data":{"version":3,"payload":{"id":"a1","title":"Lama"}}}`,
+			patch: `{"title":"Baru"}`,
+			want: `{"specversion":"1.0","id":"e1","type":"com.news.article.updated","source":"news_service",` +
+				`"data":{"version":3,"payload":{"id":"a1","title":"Baru"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := patchEventPayload([]byte(tt.body), json.RawMessage(tt.patch))
+			if err != nil {
+				t.Fatalf("patchEventPayload returned error: %v", err)
+			}
+			var gotValue, wantValue interface{}
+			if err := json.Unmarshal(got, &gotValue); err != nil {
+				t.Fatalf("result is not valid JSON: %v (%s)", err, got)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &wantValue); err != nil {
+				t.Fatalf("expected value is not valid JSON: %v", err)
+			}
+			if !reflect.DeepEqual(gotValue, wantValue) {
+				t.Errorf("patchEventPayload\n got: %s\nwant: %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchEventPayloadRejectsNonObjectBody(t *testing.T) {
+	for _, body := range []string{`not json`, `["a"]`, `"text"`, `{"specversion":"1.0","data":"text"}`} {
+		if got, err := patchEventPayload([]byte(body), json.RawMessage(`{"title":"Baru"}`)); err == nil {
+			t.Errorf("patchEventPayload(%s) = %s, want error", body, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package consumer
+
+import "log"
+
+// DedupeStats adalah ringkasan deduplikasi event untuk endpoint admin.
+type DedupeStats struct {
+	Backend          string           `json:"backend"`
+	Retention        string           `json:"retention"`
+	TrackedEvents    int              `json:"tracked_events"`
+	Duplicates       int64            `json:"duplicates"`
+	DuplicatesByType map[string]int64 `json:"duplicates_by_type"`
+}
+
+// DedupeStats mengembalikan jumlah event_id yang diingat dan jumlah pengiriman
+// duplikat yang sudah di-ack tanpa diproses sejak consumer dimulai.
+func (c *RabbitMQConsumer) DedupeStats() DedupeStats {
+	c.statsMu.Lock()
+	byType := make(map[string]int64, len(c.duplicatesByType))
+	var total int64
+	for eventType, count := range c.duplicatesByType {
+		byType[eventType] = count
+		total += count
+	}
+	c.statsMu.Unlock()
+
+	return DedupeStats{
+		Backend:          c.Dedupe.Backend(),
+		Retention:        c.Config.DedupeRetention.String(),
+		TrackedEvents:    c.Dedupe.Len(),
+		Duplicates:       total,
+		DuplicatesByType: byType,
+	}
+}
+
+// skipDuplicates meng-ack job yang event_id-nya sudah pernah diproses (atau muncul
+// dua kali dalam batch yang sama) dan mengembalikan job sisanya. Event tanpa event_id
+// selalu diproses.
+func (c *RabbitMQConsumer) skipDuplicates(jobs []eventJob) []eventJob {
+	pending := make([]eventJob, 0, len(jobs))
+	inBatch := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		eventID := job.event.DedupeKey()
+		if eventID == "" {
+			pending = append(pending, job)
+			continue
+		}
+
+		seen, err := c.Dedupe.Seen(eventID)
+		if err != nil {
+			// Lebih aman memproses ulang daripada membuang event.
+			log.Printf("Warning: Failed to check dedupe store for event %s: %v. Processing anyway.", eventID, err)
+		}
+		if seen || inBatch[eventID] {
+			log.Printf("Skipping duplicate delivery of event %s ('%s' for ID %s). Acknowledging message.",
+				eventID, job.event.Type, job.event.Payload.ID)
+			c.recordDuplicate(job.event.Type)
+			job.delivery.Ack(false)
+			continue
+		}
+		inBatch[eventID] = true
+		pending = append(pending, job)
+	}
+	return pending
+}
+
+// markProcessed mencatat kunci dedupe event setelah event berhasil diterapkan.
+func (c *RabbitMQConsumer) markProcessed(eventID string) {
+	if eventID == "" {
+		return
+	}
+	if err := c.Dedupe.Mark(eventID); err != nil {
+		log.Printf("Warning: Failed to record event %s in dedupe store: %v", eventID, err)
+	}
+}
+
+func (c *RabbitMQConsumer) recordDuplicate(eventType string) {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	c.duplicatesByType[eventType]++
+}
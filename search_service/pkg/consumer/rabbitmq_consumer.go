@@ -2,63 +2,156 @@ package consumer
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/rabbitmq/amqp091-go"
 	"log"
 	"search_service/pkg/config"
+	"search_service/pkg/dedupe"
+	"search_service/pkg/eventschema"
 	"search_service/pkg/model"
 	"search_service/pkg/service"
+	"search_service/pkg/util"
+	"sync"
+	"time"
 )
 
+// Status koneksi consumer.
 const (
-	QueueName = "news_queue"
+	StateConnected    = "connected"
+	StateReconnecting = "reconnecting"
+	StateClosed       = "closed"
 )
 
+// ErrConsumerClosed dikembalikan jika koneksi baru selesai dibuat setelah consumer ditutup.
+var ErrConsumerClosed = errors.New("consumer is closed")
+
 type RabbitMQConsumer struct {
+	mu          sync.RWMutex
 	conn        *amqp091.Connection
 	channel     *amqp091.Channel
+	state       string
 	NewsService *service.NewsService
 	Config      *config.AppConfig
+	// Schemas mendekode dan memvalidasi event v1 maupun v2.
+	Schemas *eventschema.Registry
+	// Dedupe mengingat event_id yang sudah diproses agar pengiriman ulang di-ack tanpa efek samping.
+	Dedupe dedupe.Store
+
+	statsMu          sync.Mutex
+	duplicatesByType map[string]int64
+}
+
+func NewRabbitMQConsumer(cfg *config.AppConfig, newsService *service.NewsService, dedupeStore dedupe.Store) (*RabbitMQConsumer, error) {
+	c := &RabbitMQConsumer{
+		NewsService:      newsService,
+		Config:           cfg,
+		Schemas:          eventschema.DefaultRegistry(),
+		Dedupe:           dedupeStore,
+		duplicatesByType: make(map[string]int64),
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	log.Printf("Consumer accepts event schema versions %v.", c.Schemas.Versions())
+	return c, nil
 }
 
-func NewRabbitMQConsumer(cfg *config.AppConfig, newsService *service.NewsService) (*RabbitMQConsumer, error) {
-	conn, err := amqp091.Dial(cfg.RabbitMQURL)
+// connect membuka koneksi dan channel baru lalu mendeklarasikan ulang topologi queue.
+func (c *RabbitMQConsumer) connect() error {
+	conn, err := amqp091.Dial(c.Config.RabbitMQURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
+		return fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
 
 	ch, err := conn.Channel()
 	if err != nil {
 		conn.Close()
-		return nil, fmt.Errorf("failed to open a channel: %w", err)
+		return fmt.Errorf("failed to open a channel: %w", err)
 	}
 
-	_, err = ch.QueueDeclare(
-		QueueName,
-		true,
-		false,
-		false,
-		false,
-		nil,
-	)
-	if err != nil {
+	if err := ch.Qos(c.Config.ConsumerPrefetch, 0, false); err != nil {
+		ch.Close()
+		conn.Close()
+		return fmt.Errorf("failed to set channel prefetch: %w", err)
+	}
+
+	// Confirm mode agar salinan ke queue retry/DLQ baru dianggap tersimpan setelah di-ack broker.
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		conn.Close()
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	if err := c.declareTopology(ch); err != nil {
+		ch.Close()
+		conn.Close()
+		return err
+	}
+
+	c.mu.Lock()
+	if c.state == StateClosed {
+		// Close dipanggil saat koneksi ini dibuat; jangan pasang koneksi yang tidak akan ditutup.
+		c.mu.Unlock()
 		ch.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to declare queue '%s': %w", QueueName, err)
+		return ErrConsumerClosed
 	}
+	c.conn = conn
+	c.channel = ch
+	c.state = StateConnected
+	c.mu.Unlock()
 
-	log.Printf("Successfully connected to RabbitMQ and declared queue '%s' (Consumer)", QueueName)
+	if c.Config.RabbitMQExchange == "" {
+		log.Printf("Successfully connected to RabbitMQ and declared queue '%s' with retry and dead-letter queues (Consumer, default exchange)", c.queueName())
+	} else {
+		log.Printf("Successfully connected to RabbitMQ and bound queue '%s' to exchange '%s' with keys %v (Consumer)",
+			c.queueName(), c.Config.RabbitMQExchange, c.Config.RabbitMQBindingKeys)
+	}
+	return nil
+}
+
+// State mengembalikan status koneksi consumer saat ini.
+func (c *RabbitMQConsumer) State() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
 
-	return &RabbitMQConsumer{
-		conn:        conn,
-		channel:     ch,
-		NewsService: newsService,
-		Config:      cfg,
-	}, nil
+func (c *RabbitMQConsumer) setState(state string) {
+	c.mu.Lock()
+	c.state = state
+	c.mu.Unlock()
+}
+
+func (c *RabbitMQConsumer) currentChannel() *amqp091.Channel {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.channel
+}
+
+// OpenChannel membuka channel baru pada koneksi consumer, misalnya untuk operasi admin DLQ.
+// Pemanggil bertanggung jawab menutup channel tersebut.
+func (c *RabbitMQConsumer) OpenChannel() (*amqp091.Channel, error) {
+	c.mu.RLock()
+	conn := c.conn
+	c.mu.RUnlock()
+
+	if conn == nil || conn.IsClosed() {
+		return nil, fmt.Errorf("RabbitMQ consumer is not connected (state: %s)", c.State())
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open a channel: %w", err)
+	}
+	return ch, nil
 }
 
 func (c *RabbitMQConsumer) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.state = StateClosed
 	if c.channel != nil {
 		c.channel.Close()
 		log.Println("RabbitMQ consumer channel closed.")
@@ -69,9 +162,53 @@ func (c *RabbitMQConsumer) Close() {
 	}
 }
 
+// StartConsuming mengonsumsi pesan sampai ctx selesai. Jika koneksi atau channel
+// terputus (misalnya broker restart), consumer menyambung ulang dengan backoff
+// ber-jitter, mendeklarasikan ulang topologi dan melanjutkan konsumsi.
 func (c *RabbitMQConsumer) StartConsuming(ctx context.Context) {
-	msgs, err := c.channel.Consume(
-		QueueName,
+	backoff := util.Backoff{Min: c.Config.RabbitMQReconnectMinDelay, Max: c.Config.RabbitMQReconnectMaxDelay}
+
+	for {
+		err := c.consume(ctx)
+		if ctx.Err() != nil || c.State() == StateClosed {
+			return
+		}
+		log.Printf("RabbitMQ consumer disconnected: %v. Reconnecting...", err)
+		c.setState(StateReconnecting)
+
+		for {
+			delay := backoff.Next()
+			log.Printf("Reconnecting to RabbitMQ in %s...", delay)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			if err := c.connect(); errors.Is(err, ErrConsumerClosed) {
+				return
+			} else if err != nil {
+				log.Printf("Reconnect to RabbitMQ failed: %v", err)
+				continue
+			}
+			backoff.Reset()
+			break
+		}
+	}
+}
+
+// consume mendaftarkan consumer pada channel saat ini dan memproses pesan sampai
+// channel ditutup atau ctx selesai. Error menjelaskan penyebab terputusnya koneksi.
+func (c *RabbitMQConsumer) consume(ctx context.Context) error {
+	c.mu.RLock()
+	conn, ch := c.conn, c.channel
+	c.mu.RUnlock()
+
+	connClosed := conn.NotifyClose(make(chan *amqp091.Error, 1))
+	chanClosed := ch.NotifyClose(make(chan *amqp091.Error, 1))
+
+	msgs, err := ch.Consume(
+		c.queueName(),
 		"",
 		false,
 		false,
@@ -80,48 +217,94 @@ func (c *RabbitMQConsumer) StartConsuming(ctx context.Context) {
 		nil,
 	)
 	if err != nil {
-		log.Fatalf("Failed to register a consumer: %v", err)
+		ch.Close()
+		conn.Close()
+		return fmt.Errorf("failed to register a consumer: %w", err)
 	}
 
-	log.Println(" [*] Waiting for messages. To exit, press CTRL+C in search_service terminal.")
+	log.Printf(" [*] Waiting for messages with %d workers (prefetch %d). To exit, press CTRL+C in search_service terminal.",
+		c.Config.ConsumerWorkers, c.Config.ConsumerPrefetch)
 
-	go func() {
-		for d := range msgs {
-			log.Printf(" [x] Received a message: %s", string(d.Body))
+	pool := startWorkerPool(ctx, c.Config.ConsumerWorkers, c.Config.ConsumerPrefetch,
+		c.Config.ConsumerBatchSize, c.Config.ConsumerBatchFlush, c.processBatch)
+	defer pool.stop()
 
-			var event model.NewsEvent
-			if err := json.Unmarshal(d.Body, &event); err != nil {
-				log.Printf("Error unmarshaling message: %v. Rejecting message.", err)
-				d.Nack(false, false)
-				continue
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case amqpErr := <-connClosed:
+			return fmt.Errorf("connection closed: %v", amqpErr)
+		case amqpErr := <-chanClosed:
+			conn.Close()
+			return fmt.Errorf("channel closed: %v", amqpErr)
+		case d, ok := <-msgs:
+			if !ok {
+				conn.Close()
+				return fmt.Errorf("delivery channel closed")
 			}
-
-			switch event.Type {
-			case "CREATED":
-				log.Printf("Processing CREATED event for ID: %s", event.Payload.ID)
-				err = c.NewsService.IndexNews(ctx, event.Payload)
-			case "UPDATED":
-				log.Printf("Processing UPDATED event for ID: %s", event.Payload.ID)
-				payloadMap := make(map[string]interface{})
-				jsonBytes, _ := json.Marshal(event.Payload)
-				json.Unmarshal(jsonBytes, &payloadMap)
-				err = c.NewsService.UpdateNews(ctx, event.Payload.ID, payloadMap)
-			case "DELETED":
-				log.Printf("Processing DELETED event for ID: %s", event.Payload.ID)
-				err = c.NewsService.DeleteNews(ctx, event.Payload.ID)
-			default:
-				log.Printf("Unknown event type: %s for ID: %s. Rejecting message.", event.Type, event.Payload.ID)
-				d.Nack(false, false)
-				continue
+			if event, ok := c.decodeDelivery(ctx, d); ok {
+				pool.dispatch(eventJob{delivery: d, event: event})
 			}
+		}
+	}
+}
 
-			if err != nil {
-				log.Printf("Failed to process event '%s' for ID %s: %v. Message will be Nacked (and potentially re-queued/dead-lettered).", event.Type, event.Payload.ID, err)
-				d.Nack(false, true)
-			} else {
-				log.Printf("Successfully processed event '%s' for ID: %s. Acknowledging message.", event.Type, event.Payload.ID)
-				d.Ack(false)
-			}
+// decodeDelivery mem-parsing pesan format lama maupun CloudEvents (structured atau
+// binary), menaikkan skemanya ke versi terbaru dan memvalidasinya. Pesan yang tidak
+// dapat di-parse, versi skemanya tidak didukung atau tidak valid langsung dipindahkan
+// ke DLQ dan ok bernilai false.
+func (c *RabbitMQConsumer) decodeDelivery(ctx context.Context, d amqp091.Delivery) (model.NewsEvent, bool) {
+	log.Printf(" [x] Received a message: %s", string(d.Body))
+
+	event, format, err := c.Schemas.DecodeMessage(d.ContentType, d.Headers, d.Body)
+	if err != nil {
+		log.Printf("Rejecting %s message: %v. Moving message to dead-letter queue.", format, err)
+		c.deadLetter(ctx, d, err.Error())
+		return event, false
+	}
+	log.Printf("Decoded %s event %s (type: %s, source: %s, subject: %s)",
+		format, event.EventID, event.Type, valueOrDash(event.Source), valueOrDash(event.Subject))
+	return event, true
+}
+
+// processBatch menerapkan satu batch event lewat _bulk, lalu meng-ack atau me-retry
+// setiap pesan berdasarkan hasil item-nya masing-masing.
+func (c *RabbitMQConsumer) processBatch(ctx context.Context, jobs []eventJob) {
+	jobs = c.skipDuplicates(jobs)
+	if len(jobs) == 0 {
+		return
+	}
+
+	events := make([]model.NewsEvent, len(jobs))
+	for i, job := range jobs {
+		log.Printf("Processing %s event for ID: %s", job.event.Type, job.event.Payload.ID)
+		events[i] = job.event
+	}
+
+	errs := c.NewsService.ApplyEvents(ctx, events)
+	for i, job := range jobs {
+		event := job.event
+		err := errs[i]
+		switch {
+		case err != nil && service.IsPermanent(err):
+			log.Printf("Failed to process event '%s' for ID %s: %v. Not retrying.", event.Type, event.Payload.ID, err)
+			c.deadLetter(ctx, job.delivery, err.Error())
+			continue
+		case err != nil:
+			log.Printf("Failed to process event '%s' for ID %s: %v. Scheduling retry.", event.Type, event.Payload.ID, err)
+			c.retryOrDeadLetter(ctx, job.delivery, err)
+			continue
 		}
-	}()
+		log.Printf("Successfully processed event '%s' for ID: %s. Acknowledging message.", event.Type, event.Payload.ID)
+		c.markProcessed(event.DedupeKey())
+		job.delivery.Ack(false)
+	}
+}
+
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
 }
@@ -0,0 +1,184 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	// Header yang dibawa pesan selama siklus retry dan dead-letter.
+	HeaderRetryCount     = "x-retry-count"
+	HeaderLastError      = "x-last-error"
+	HeaderDeadLetterAt   = "x-dead-lettered-at"
+	HeaderDeadLetterWhy  = "x-dead-letter-reason"
+	HeaderOriginalQueue  = "x-original-queue"
+	HeaderFirstFailureAt = "x-first-failure-at"
+)
+
+// publishConfirmTimeout adalah batas waktu menunggu konfirmasi broker saat memindahkan
+// pesan ke queue retry, DLQ atau kembali ke queue utama.
+const publishConfirmTimeout = 5 * time.Second
+
+// queueName mengembalikan queue milik consumer ini (RABBITMQ_QUEUE).
+func (c *RabbitMQConsumer) queueName() string {
+	return c.Config.RabbitMQQueue
+}
+
+// deadLetterQueueName mengembalikan DLQ untuk queue consumer ini.
+func (c *RabbitMQConsumer) deadLetterQueueName() string {
+	return c.queueName() + ".dlq"
+}
+
+// retryQueueName memberi nama queue delay berdasarkan durasinya, sehingga perubahan
+// konfigurasi jeda membuat queue baru alih-alih bentrok dengan argumen queue lama.
+func (c *RabbitMQConsumer) retryQueueName(delay time.Duration) string {
+	return fmt.Sprintf("%s.retry.%s", c.queueName(), delay)
+}
+
+// declareTopology mendeklarasikan topic exchange beserta binding queue utama (kecuali
+// pada mode kompatibilitas tanpa exchange), queue retry (TTL + dead-letter kembali ke
+// queue utama) dan dead-letter queue. Retry dan DLQ selalu memakai default exchange
+// langsung ke queue milik consumer ini, sehingga pesan yang diulang tidak terkirim lagi
+// ke consumer lain yang ikut bind ke exchange.
+func (c *RabbitMQConsumer) declareTopology(ch *amqp091.Channel) error {
+	queue := c.queueName()
+	if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare queue '%s': %w", queue, err)
+	}
+
+	if exchange := c.Config.RabbitMQExchange; exchange != "" {
+		if err := ch.ExchangeDeclare(exchange, amqp091.ExchangeTopic, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange '%s': %w", exchange, err)
+		}
+		for _, key := range c.Config.RabbitMQBindingKeys {
+			if err := ch.QueueBind(queue, key, exchange, false, nil); err != nil {
+				return fmt.Errorf("failed to bind queue '%s' to exchange '%s' with key '%s': %w", queue, exchange, key, err)
+			}
+		}
+	}
+
+	for _, delay := range c.Config.ConsumerRetryDelays {
+		name := c.retryQueueName(delay)
+		args := amqp091.Table{
+			"x-message-ttl":             delay.Milliseconds(),
+			"x-dead-letter-exchange":    "",
+			"x-dead-letter-routing-key": queue,
+		}
+		if _, err := ch.QueueDeclare(name, true, false, false, false, args); err != nil {
+			return fmt.Errorf("failed to declare retry queue '%s': %w", name, err)
+		}
+	}
+
+	dlq := c.deadLetterQueueName()
+	if _, err := ch.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue '%s': %w", dlq, err)
+	}
+	return nil
+}
+
+// retryOrDeadLetter menjadwalkan ulang pesan yang gagal ke queue retry dengan jeda yang
+// makin panjang, atau memindahkannya ke DLQ jika percobaan sudah habis. Pesan asli
+// di-ack hanya setelah broker mengonfirmasi salinannya; jika tidak, pesan di-requeue.
+func (c *RabbitMQConsumer) retryOrDeadLetter(ctx context.Context, d amqp091.Delivery, cause error) {
+	attempts := retryCount(d.Headers) + 1
+	if attempts >= c.Config.ConsumerMaxAttempts || len(c.Config.ConsumerRetryDelays) == 0 {
+		c.deadLetter(ctx, d, fmt.Sprintf("max attempts (%d) exhausted: %v", c.Config.ConsumerMaxAttempts, cause))
+		return
+	}
+
+	delays := c.Config.ConsumerRetryDelays
+	delay := delays[min(attempts-1, len(delays)-1)]
+
+	headers := copyHeaders(d.Headers)
+	headers[HeaderRetryCount] = int32(attempts)
+	headers[HeaderLastError] = cause.Error()
+	if _, ok := headers[HeaderFirstFailureAt]; !ok {
+		headers[HeaderFirstFailureAt] = time.Now().UTC().Format(time.RFC3339)
+	}
+
+	if err := c.republish(ctx, c.retryQueueName(delay), d, headers); err != nil {
+		log.Printf("Failed to schedule retry for message: %v. Requeueing message instead.", err)
+		d.Nack(false, true)
+		return
+	}
+	log.Printf("Message scheduled for retry %d/%d in %s.", attempts, c.Config.ConsumerMaxAttempts-1, delay)
+	d.Ack(false)
+}
+
+// deadLetter memindahkan pesan ke DLQ beserta alasan kegagalannya.
+func (c *RabbitMQConsumer) deadLetter(ctx context.Context, d amqp091.Delivery, reason string) {
+	headers := copyHeaders(d.Headers)
+	headers[HeaderDeadLetterWhy] = reason
+	headers[HeaderDeadLetterAt] = time.Now().UTC().Format(time.RFC3339)
+	headers[HeaderOriginalQueue] = c.queueName()
+	if d.MessageId == "" {
+		// DLQ admin API mengidentifikasi pesan berdasarkan MessageId.
+		d.MessageId = newMessageID()
+	}
+
+	if err := c.republish(ctx, c.deadLetterQueueName(), d, headers); err != nil {
+		log.Printf("Failed to dead-letter message: %v. Requeueing message instead.", err)
+		d.Nack(false, true)
+		return
+	}
+	log.Printf("Message moved to dead-letter queue '%s': %s", c.deadLetterQueueName(), reason)
+	d.Ack(false)
+}
+
+func (c *RabbitMQConsumer) republish(ctx context.Context, queue string, d amqp091.Delivery, headers amqp091.Table) error {
+	return publishConfirmed(ctx, c.currentChannel(), queue, amqp091.Publishing{
+		Headers:      headers,
+		ContentType:  d.ContentType,
+		MessageId:    d.MessageId,
+		Timestamp:    d.Timestamp,
+		Type:         d.Type,
+		Body:         d.Body,
+		DeliveryMode: amqp091.Persistent,
+	})
+}
+
+// publishConfirmed mengirim pesan lewat default exchange langsung ke queue dan menunggu
+// broker mengonfirmasinya. ch harus dalam confirm mode. Error (termasuk nack dan
+// timeout) berarti salinan belum tentu tersimpan, sehingga pesan asli belum boleh di-ack.
+func publishConfirmed(ctx context.Context, ch *amqp091.Channel, queue string, msg amqp091.Publishing) error {
+	ctx, cancel := context.WithTimeout(ctx, publishConfirmTimeout)
+	defer cancel()
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, msg)
+	if err != nil {
+		return fmt.Errorf("failed to publish to queue '%s': %w", queue, err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("no confirm from broker for queue '%s': %w", queue, err)
+	}
+	if !acked {
+		return fmt.Errorf("broker rejected message for queue '%s'", queue)
+	}
+	return nil
+}
+
+func retryCount(headers amqp091.Table) int {
+	switch v := headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+func copyHeaders(headers amqp091.Table) amqp091.Table {
+	copied := make(amqp091.Table, len(headers)+4)
+	for k, v := range headers {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"context"
+	"hash/fnv"
+	"search_service/pkg/model"
+	"sync"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// eventJob adalah pesan yang sudah di-decode dan siap diproses oleh worker.
+type eventJob struct {
+	delivery amqp091.Delivery
+	event    model.NewsEvent
+}
+
+// workerPool memproses event secara paralel. Event untuk ID artikel yang sama selalu
+// di-hash ke worker yang sama sehingga urutannya terjaga (UPDATED tidak pernah
+// mendahului CREATED), sementara artikel yang berbeda diproses bersamaan.
+// Setiap worker mengumpulkan event menjadi batch yang di-flush berdasarkan ukuran
+// atau waktu.
+type workerPool struct {
+	queues []chan eventJob
+	wg     sync.WaitGroup
+}
+
+func startWorkerPool(ctx context.Context, size, buffer, batchSize int, flushInterval time.Duration, handle func(ctx context.Context, jobs []eventJob)) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	p := &workerPool{queues: make([]chan eventJob, size)}
+	for i := range p.queues {
+		queue := make(chan eventJob, buffer)
+		p.queues[i] = queue
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			runBatchWorker(ctx, queue, batchSize, flushInterval, handle)
+		}()
+	}
+	return p
+}
+
+func runBatchWorker(ctx context.Context, queue <-chan eventJob, batchSize int, flushInterval time.Duration, handle func(ctx context.Context, jobs []eventJob)) {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]eventJob, 0, batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		handle(ctx, batch)
+		batch = make([]eventJob, 0, batchSize)
+	}
+
+	for {
+		select {
+		case job, ok := <-queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, job)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+func (p *workerPool) dispatch(job eventJob) {
+	p.queues[workerIndex(job.event.Payload.ID, len(p.queues))] <- job
+}
+
+// stop menutup antrean worker dan menunggu semua job yang sudah diterima selesai.
+func (p *workerPool) stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+func workerIndex(id string, size int) int {
+	h := fnv.New32a()
+	h.Write([]byte(id))
+	return int(h.Sum32() % uint32(size))
+}
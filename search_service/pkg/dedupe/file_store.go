@@ -0,0 +1,172 @@
+package dedupe
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// compactMinLines adalah jumlah baris minimum sebelum file dipadatkan ulang.
+const compactMinLines = 1024
+
+// FileStore menyimpan event_id di memori dan mencatat setiap penandaan ke file
+// append-only ("<unix nano> <event_id>" per baris) sehingga bertahan saat restart.
+// File ditulis ulang tanpa entri kedaluwarsa saat dibuka dan ketika jumlah barisnya
+// jauh melebihi jumlah entri yang masih berlaku.
+type FileStore struct {
+	w     *window
+	path  string
+	file  *os.File
+	lines int
+}
+
+// OpenFileStore memuat entri yang belum kedaluwarsa dari path (jika ada) lalu membuka
+// file untuk ditambahi.
+func OpenFileStore(path string, retention time.Duration) (*FileStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create dedupe store directory: %w", err)
+		}
+	}
+
+	s := &FileStore{w: newWindow(retention), path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	if err := s.compactLocked(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileStore) load() error {
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open dedupe store: %w", err)
+	}
+	defer f.Close()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		stamp, eventID, ok := strings.Cut(scanner.Text(), " ")
+		if !ok || eventID == "" {
+			continue // Baris rusak (mis. tulisan terakhir terpotong) dilewati
+		}
+		nanos, err := strconv.ParseInt(stamp, 10, 64)
+		if err != nil {
+			continue
+		}
+		markedAt := time.Unix(0, nanos)
+		if !s.w.expired(markedAt, now) {
+			s.w.seen[eventID] = markedAt
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read dedupe store: %w", err)
+	}
+	return nil
+}
+
+// compactLocked menulis ulang file hanya dengan entri yang masih berlaku lewat file
+// sementara dan rename, lalu membukanya kembali untuk ditambahi.
+func (s *FileStore) compactLocked() error {
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create dedupe store file: %w", err)
+	}
+	writer := bufio.NewWriter(tmp)
+	for eventID, markedAt := range s.w.seen {
+		fmt.Fprintf(writer, "%d %s\n", markedAt.UnixNano(), eventID)
+	}
+	if err := writer.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write dedupe store file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync dedupe store file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close dedupe store file: %w", err)
+	}
+
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	renameErr := os.Rename(tmpPath, s.path)
+
+	// Tetap buka file (lama atau baru) agar penandaan berikutnya tidak gagal.
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if renameErr != nil {
+		s.file = file
+		return fmt.Errorf("failed to replace dedupe store file: %w", renameErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open dedupe store for append: %w", err)
+	}
+	s.file = file
+	s.lines = len(s.w.seen)
+	return nil
+}
+
+func (s *FileStore) Seen(eventID string) (bool, error) {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	return s.w.seenLocked(eventID, time.Now()), nil
+}
+
+func (s *FileStore) Mark(eventID string) error {
+	if eventID == "" || strings.ContainsAny(eventID, " \t\r\n") {
+		return fmt.Errorf("invalid event id %q", eventID)
+	}
+
+	now := time.Now()
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	if s.file == nil {
+		return errors.New("dedupe store is closed")
+	}
+
+	if _, err := fmt.Fprintf(s.file, "%d %s\n", now.UnixNano(), eventID); err != nil {
+		return fmt.Errorf("failed to append to dedupe store: %w", err)
+	}
+	s.w.seen[eventID] = now
+	s.lines++
+
+	if s.w.pruneLocked(now) && s.lines > compactMinLines && s.lines > 2*len(s.w.seen) {
+		if err := s.compactLocked(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *FileStore) Len() int {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	return len(s.w.seen)
+}
+
+func (s *FileStore) Backend() string { return BackendFile }
+
+func (s *FileStore) Close() error {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,164 @@
+package dedupe
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read store file: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil
+	}
+	return lines
+}
+
+func TestFileStoreReplaysMarksAfterReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedupe", "events.log")
+	s, err := OpenFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileStore returned error: %v", err)
+	}
+	for _, id := range []string{"e1", "e2", "e3"} {
+		if err := s.Mark(id); err != nil {
+			t.Fatalf("Mark(%q) returned error: %v", id, err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+
+	reopened, err := OpenFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileStore (reopen) returned error: %v", err)
+	}
+	defer reopened.Close()
+
+	tests := []struct {
+		id   string
+		want bool
+	}{
+		{"e1", true},
+		{"e2", true},
+		{"e3", true},
+		{"e4", false},
+	}
+	for _, tt := range tests {
+		if got, _ := reopened.Seen(tt.id); got != tt.want {
+			t.Errorf("Seen(%q) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+	if got := reopened.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+}
+
+func TestFileStoreLoadSkipsExpiredAndCorruptLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.log")
+	now := time.Now()
+	content := strings.Join([]string{
+		fmt.Sprintf("%d fresh", now.Add(-time.Minute).UnixNano()),
+		fmt.Sprintf("%d expired", now.Add(-2*time.Hour).UnixNano()),
+		"not-a-timestamp broken",
+		fmt.Sprintf("%d", now.UnixNano()),
+		"",
+		fmt.Sprintf("%d also-fresh", now.UnixNano()),
+		fmt.Sprintf("%d trunc", now.UnixNano())[:8], // Tulisan terakhir terpotong
+	}, "\n")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write store file: %v", err)
+	}
+
+	s, err := OpenFileStore(path, time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileStore returned error: %v", err)
+	}
+	defer s.Close()
+
+	for id, want := range map[string]bool{"fresh": true, "also-fresh": true, "expired": false, "broken": false} {
+		if got, _ := s.Seen(id); got != want {
+			t.Errorf("Seen(%q) = %v, want %v", id, got, want)
+		}
+	}
+
+	// File ditulis ulang saat dibuka sehingga hanya entri yang berlaku yang tersisa.
+	var ids []string
+	for _, line := range readLines(t, path) {
+		_, id, _ := strings.Cut(line, " ")
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	if want := []string{"also-fresh", "fresh"}; !slices.Equal(ids, want) {
+		t.Errorf("compacted file contains %v, want %v", ids, want)
+	}
+}
+
+func TestFileStoreCompactsWhenMostLinesExpired(t *testing.T) {
+	const retention = 200 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "events.log")
+	s, err := OpenFileStore(path, retention)
+	if err != nil {
+		t.Fatalf("OpenFileStore returned error: %v", err)
+	}
+	defer s.Close()
+
+	for i := range compactMinLines + 10 {
+		if err := s.Mark(fmt.Sprintf("old-%d", i)); err != nil {
+			t.Fatalf("Mark returned error: %v", err)
+		}
+	}
+	if got := len(readLines(t, path)); got != compactMinLines+10 {
+		t.Fatalf("file has %d lines before compaction, want %d", got, compactMinLines+10)
+	}
+
+	time.Sleep(retention + 50*time.Millisecond)
+	if err := s.Mark("new"); err != nil {
+		t.Fatalf("Mark returned error: %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 1 || !strings.HasSuffix(lines[0], " new") {
+		t.Errorf("compacted file = %q, want only the entry for 'new'", lines)
+	}
+	if got := s.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+
+	// Penandaan setelah compaction tetap ditambahkan ke file baru.
+	if err := s.Mark("after"); err != nil {
+		t.Fatalf("Mark returned error: %v", err)
+	}
+	if got := len(readLines(t, path)); got != 2 {
+		t.Errorf("file has %d lines after compaction and one more mark, want 2", got)
+	}
+}
+
+func TestFileStoreRejectsInvalidEventIDs(t *testing.T) {
+	s, err := OpenFileStore(filepath.Join(t.TempDir(), "events.log"), time.Hour)
+	if err != nil {
+		t.Fatalf("OpenFileStore returned error: %v", err)
+	}
+	defer s.Close()
+
+	for _, id := range []string{"", "with space", "with\ttab", "with\nnewline"} {
+		if err := s.Mark(id); err == nil {
+			t.Errorf("Mark(%q) succeeded, want error", id)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close returned error: %v", err)
+	}
+	if err := s.Mark("e1"); err == nil {
+		t.Error("Mark after Close succeeded, want error")
+	}
+}
@@ -0,0 +1,122 @@
+// Package dedupe mencatat event_id yang sudah berhasil diproses agar pengiriman ulang
+// (at-least-once) dari RabbitMQ dapat dikenali dan di-ack tanpa efek samping.
+package dedupe
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Backend store yang didukung.
+const (
+	BackendMemory = "memory"
+	BackendFile   = "file"
+)
+
+// Store menyimpan event_id yang sudah diproses selama jendela retensi tertentu.
+type Store interface {
+	// Seen melaporkan apakah event_id sudah pernah ditandai dan belum kedaluwarsa.
+	Seen(eventID string) (bool, error)
+	// Mark menandai event_id sebagai sudah diproses.
+	Mark(eventID string) error
+	// Len mengembalikan jumlah event_id yang masih disimpan.
+	Len() int
+	Backend() string
+	Close() error
+}
+
+// NewStore membuat store sesuai backend: "memory" atau "file" (path dipakai untuk file).
+func NewStore(backend, path string, retention time.Duration) (Store, error) {
+	switch backend {
+	case BackendMemory:
+		return NewMemoryStore(retention), nil
+	case BackendFile:
+		return OpenFileStore(path, retention)
+	default:
+		return nil, fmt.Errorf("unknown dedupe store backend '%s'", backend)
+	}
+}
+
+// window adalah kumpulan event_id beserta waktu penandaannya, dipakai bersama oleh
+// kedua implementasi store.
+type window struct {
+	mu        sync.Mutex
+	retention time.Duration
+	seen      map[string]time.Time
+	lastPrune time.Time
+}
+
+func newWindow(retention time.Duration) *window {
+	return &window{
+		retention: retention,
+		seen:      make(map[string]time.Time),
+		lastPrune: time.Now(),
+	}
+}
+
+func (w *window) expired(markedAt, now time.Time) bool {
+	return now.Sub(markedAt) > w.retention
+}
+
+// seenLocked harus dipanggil dengan w.mu terkunci.
+func (w *window) seenLocked(eventID string, now time.Time) bool {
+	markedAt, ok := w.seen[eventID]
+	if !ok {
+		return false
+	}
+	if w.expired(markedAt, now) {
+		delete(w.seen, eventID)
+		return false
+	}
+	return true
+}
+
+// pruneLocked membuang entri kedaluwarsa paling sering sekali per sepersepuluh retensi.
+// Mengembalikan true jika pembersihan dijalankan.
+func (w *window) pruneLocked(now time.Time) bool {
+	if now.Sub(w.lastPrune) < w.retention/10 {
+		return false
+	}
+	for id, markedAt := range w.seen {
+		if w.expired(markedAt, now) {
+			delete(w.seen, id)
+		}
+	}
+	w.lastPrune = now
+	return true
+}
+
+// MemoryStore menyimpan event_id di memori; isinya hilang saat proses berhenti.
+type MemoryStore struct {
+	w *window
+}
+
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{w: newWindow(retention)}
+}
+
+func (s *MemoryStore) Seen(eventID string) (bool, error) {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	return s.w.seenLocked(eventID, time.Now()), nil
+}
+
+func (s *MemoryStore) Mark(eventID string) error {
+	now := time.Now()
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	s.w.seen[eventID] = now
+	s.w.pruneLocked(now)
+	return nil
+}
+
+func (s *MemoryStore) Len() int {
+	s.w.mu.Lock()
+	defer s.w.mu.Unlock()
+	return len(s.w.seen)
+}
+
+func (s *MemoryStore) Backend() string { return BackendMemory }
+
+func (s *MemoryStore) Close() error { return nil }
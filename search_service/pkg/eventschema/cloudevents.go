@@ -0,0 +1,148 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"search_service/pkg/model"
+	"strconv"
+	"strings"
+)
+
+// Atribut CloudEvents 1.0 dan binding AMQP-nya. Pada mode structured seluruh event
+// (atribut dan data) ada di body dengan content type CloudEventsContentType; pada mode
+// binary atribut dikirim sebagai header berawalan CloudEventsHeaderPrefix dan body
+// hanya berisi data.
+const (
+	CloudEventsSpecVersion  = "1.0"
+	CloudEventsContentType  = "application/cloudevents+json"
+	CloudEventsHeaderPrefix = "cloudEvents:"
+	CloudEventTypePrefix    = "com.news.article."
+)
+
+// Format pesan yang dikenali consumer.
+const (
+	FormatLegacy               = "legacy"
+	FormatCloudEventStructured = "cloudevents-structured"
+	FormatCloudEventBinary     = "cloudevents-binary"
+)
+
+// DetectFormat menentukan format pesan dari content type dan header AMQP.
+func DetectFormat(contentType string, headers map[string]interface{}) string {
+	if strings.HasPrefix(strings.ToLower(contentType), CloudEventsContentType) {
+		return FormatCloudEventStructured
+	}
+	if _, ok := headers[CloudEventsHeaderPrefix+"specversion"]; ok {
+		return FormatCloudEventBinary
+	}
+	return FormatLegacy
+}
+
+// DecodeMessage mendekode pesan dalam format apa pun yang dikenali. Data CloudEvents
+// berisi field NewsEvent selain yang sudah diwakili atribut (id, type, time, source,
+// subject), lalu diproses dengan upcaster dan validator yang sama seperti format lama.
+func (r *Registry) DecodeMessage(contentType string, headers map[string]interface{}, body []byte) (model.NewsEvent, string, error) {
+	format := DetectFormat(contentType, headers)
+	switch format {
+	case FormatCloudEventStructured:
+		event, err := r.decodeStructuredCloudEvent(body)
+		return event, format, err
+	case FormatCloudEventBinary:
+		event, err := r.decodeBinaryCloudEvent(headers, body)
+		return event, format, err
+	default:
+		event, err := r.Decode(body)
+		return event, format, err
+	}
+}
+
+func (r *Registry) decodeStructuredCloudEvent(body []byte) (model.NewsEvent, error) {
+	envelope, err := parseObject(body)
+	if err != nil {
+		return model.NewsEvent{}, fmt.Errorf("failed to parse cloudevent: %w", err)
+	}
+	data, ok := envelope["data"].(map[string]interface{})
+	if !ok {
+		return model.NewsEvent{}, errors.New("cloudevent data must be a JSON object")
+	}
+
+	attributes := make(map[string]string, len(envelope))
+	for name, value := range envelope {
+		if text, ok := value.(string); ok {
+			attributes[name] = text
+		}
+	}
+	return r.decodeCloudEvent(attributes, data)
+}
+
+func (r *Registry) decodeBinaryCloudEvent(headers map[string]interface{}, body []byte) (model.NewsEvent, error) {
+	data, err := parseObject(body)
+	if err != nil {
+		return model.NewsEvent{}, fmt.Errorf("failed to parse cloudevent data: %w", err)
+	}
+
+	attributes := make(map[string]string, len(headers))
+	for name, value := range headers {
+		attribute, ok := strings.CutPrefix(name, CloudEventsHeaderPrefix)
+		if !ok {
+			continue
+		}
+		if text, ok := value.(string); ok {
+			attributes[attribute] = text
+		}
+	}
+	return r.decodeCloudEvent(attributes, data)
+}
+
+// decodeCloudEvent memetakan atribut CloudEvents ke field NewsEvent: id -> event_id,
+// type -> type, time -> occurred_at, source -> source dan subject -> subject (ID artikel).
+func (r *Registry) decodeCloudEvent(attributes map[string]string, data map[string]interface{}) (model.NewsEvent, error) {
+	if specVersion := attributes["specversion"]; specVersion != CloudEventsSpecVersion {
+		return model.NewsEvent{}, fmt.Errorf("unsupported cloudevents specversion '%s'", specVersion)
+	}
+	for _, name := range []string{"id", "source", "type"} {
+		if attributes[name] == "" {
+			return model.NewsEvent{}, fmt.Errorf("cloudevent attribute '%s' is required", name)
+		}
+	}
+
+	raw := make(map[string]interface{}, len(data)+6)
+	for field, value := range data {
+		raw[field] = value
+	}
+	// Envelope CloudEvents diperkenalkan bersama skema v2. Nilainya json.Number seperti
+	// hasil parseObject agar dibaca schemaVersion.
+	if _, ok := raw["schema_version"]; !ok {
+		raw["schema_version"] = json.Number(strconv.Itoa(model.SchemaVersionV2))
+	}
+	raw["event_id"] = attributes["id"]
+	raw["type"] = eventTypeFromCloudEvent(attributes["type"])
+	raw["source"] = attributes["source"]
+	if occurredAt := attributes["time"]; occurredAt != "" {
+		raw["occurred_at"] = occurredAt
+	}
+
+	if subject := attributes["subject"]; subject != "" {
+		raw["subject"] = subject
+		payload, _ := raw["payload"].(map[string]interface{})
+		if payload == nil {
+			payload = make(map[string]interface{})
+			raw["payload"] = payload
+		}
+		if id, _ := payload["id"].(string); id == "" {
+			payload["id"] = subject
+		} else if id != subject {
+			return model.NewsEvent{}, fmt.Errorf("cloudevent subject '%s' does not match payload.id '%s'", subject, id)
+		}
+	}
+	return r.decodeRaw(raw)
+}
+
+// eventTypeFromCloudEvent mengubah "com.news.article.created" menjadi "CREATED". Tipe di
+// luar prefix dikembalikan apa adanya agar validator melaporkannya sebagai tidak dikenal.
+func eventTypeFromCloudEvent(ceType string) string {
+	if suffix, ok := strings.CutPrefix(ceType, CloudEventTypePrefix); ok && suffix != "" {
+		return strings.ToUpper(suffix)
+	}
+	return ceType
+}
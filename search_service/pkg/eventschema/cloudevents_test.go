@@ -0,0 +1,166 @@
+package eventschema
+
+import (
+	"search_service/pkg/model"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeMessage(t *testing.T) {
+	occurredAt := time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC)
+	binaryHeaders := func(extra map[string]interface{}) map[string]interface{} {
+		headers := map[string]interface{}{
+			"cloudEvents:specversion": "1.0",
+			"cloudEvents:id":          "e1",
+			"cloudEvents:type":        "com.news.article.updated",
+			"cloudEvents:source":      "/news_service",
+			"cloudEvents:subject":     "a1",
+			"cloudEvents:time":        "2024-03-01T08:30:00Z",
+			"x-retry-count":           int32(1),
+		}
+		for name, value := range extra {
+			headers[name] = value
+		}
+		return headers
+	}
+	updated := model.NewsEvent{
+		SchemaVersion: 2, EventID: "e1", Type: model.EventUpdated, OccurredAt: occurredAt, Version: 7,
+		Source: "/news_service", Subject: "a1", UpdateMask: []string{"tags"},
+		Payload: model.DocumentNews{ID: "a1", Tags: []string{"x"}},
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		headers     map[string]interface{}
+		body        string
+		wantFormat  string
+		want        model.NewsEvent
+	}{
+		{
+			name:        "structured",
+			contentType: "application/cloudevents+json; charset=utf-8",
+			body: `{"specversion":"1.0","id":"e1","type":"com.news.article.updated","source":"/news_service",` +
+				`"subject":"a1","time":"2024-03-01T08:30:00Z","datacontenttype":"application/json",` +
+				`"data":{"version":7,"update_mask":["tags"],"payload":{"tags":["x"]}}}`,
+			wantFormat: FormatCloudEventStructured,
+			want:       updated,
+		},
+		{
+			name:        "binary",
+			contentType: "application/json",
+			headers:     binaryHeaders(nil),
+			body:        `{"version":7,"update_mask":["tags"],"payload":{"id":"a1","tags":["x"]}}`,
+			wantFormat:  FormatCloudEventBinary,
+			want:        updated,
+		},
+		{
+			name:        "legacy v2",
+			contentType: "application/json",
+			body: `{"schema_version":2,"event_id":"e1","type":"DELETED","occurred_at":"2024-03-01T08:30:00Z",` +
+				`"version":7,"payload":{"id":"a1"}}`,
+			wantFormat: FormatLegacy,
+			want: model.NewsEvent{
+				SchemaVersion: 2, EventID: "e1", Type: model.EventDeleted, OccurredAt: occurredAt, Version: 7,
+				Payload: model.DocumentNews{ID: "a1"},
+			},
+		},
+		{
+			name: "legacy v1 without content type",
+			body: `{"event_id":"e1","type":"DELETED","timestamp":"2024-03-01T08:30:00Z","payload":{"id":"a1"}}`,
+			headers: map[string]interface{}{
+				"x-retry-count": int32(2),
+			},
+			wantFormat: FormatLegacy,
+			want: model.NewsEvent{
+				SchemaVersion: 2, EventID: "e1", Type: model.EventDeleted, OccurredAt: occurredAt,
+				Version: occurredAt.UnixNano(), Payload: model.DocumentNews{ID: "a1"},
+			},
+		},
+	}
+
+	registry := DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, format, err := registry.DecodeMessage(tt.contentType, tt.headers, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("DecodeMessage returned error: %v", err)
+			}
+			if format != tt.wantFormat {
+				t.Errorf("format = %q, want %q", format, tt.wantFormat)
+			}
+			assertEvent(t, got, tt.want)
+		})
+	}
+}
+
+func TestDecodeMessageErrors(t *testing.T) {
+	const structured = "application/cloudevents+json"
+	binary := map[string]interface{}{
+		"cloudEvents:specversion": "1.0",
+		"cloudEvents:id":          "e1",
+		"cloudEvents:type":        "com.news.article.deleted",
+		"cloudEvents:source":      "/news_service",
+		"cloudEvents:subject":     "a1",
+		"cloudEvents:time":        "2024-03-01T08:30:00Z",
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		headers     map[string]interface{}
+		body        string
+		contains    string
+	}{
+		{
+			name: "structured data is not an object", contentType: structured,
+			body:     `{"specversion":"1.0","id":"e1","type":"com.news.article.deleted","source":"s","data":"a1"}`,
+			contains: "data must be a JSON object",
+		},
+		{
+			name: "unsupported specversion", contentType: structured,
+			body:     `{"specversion":"0.3","id":"e1","type":"com.news.article.deleted","source":"s","data":{}}`,
+			contains: "unsupported cloudevents specversion '0.3'",
+		},
+		{
+			name: "missing source", contentType: structured,
+			body:     `{"specversion":"1.0","id":"e1","type":"com.news.article.deleted","data":{}}`,
+			contains: "attribute 'source' is required",
+		},
+		{
+			name: "unknown type", contentType: structured,
+			body: `{"specversion":"1.0","id":"e1","type":"com.example.other","source":"s","subject":"a1",` +
+				`"time":"2024-03-01T08:30:00Z","data":{"version":1}}`,
+			contains: "unknown event type 'com.example.other'",
+		},
+		{
+			name: "subject does not match payload id", headers: binary,
+			body:     `{"version":1,"payload":{"id":"a2"}}`,
+			contains: "subject 'a1' does not match payload.id 'a2'",
+		},
+		{
+			name: "binary body is not json", headers: binary,
+			body:     `a1`,
+			contains: "failed to parse cloudevent data",
+		},
+		{
+			name:     "legacy body is not json",
+			body:     `a1`,
+			contains: "failed to parse event",
+		},
+	}
+
+	registry := DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := registry.DecodeMessage(tt.contentType, tt.headers, []byte(tt.body))
+			if err == nil {
+				t.Fatalf("DecodeMessage(%s) succeeded, want error", tt.body)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("DecodeMessage(%s) error = %q, want it to contain %q", tt.body, err, tt.contains)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Package eventschema mendekode NewsEvent dari berbagai versi skema. Event versi lama
+// dinaikkan satu versi demi satu versi oleh upcaster yang terdaftar sampai mencapai
+// model.CurrentSchemaVersion, lalu divalidasi.
+package eventschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"search_service/pkg/model"
+)
+
+// ErrUnsupportedSchemaVersion dikembalikan untuk event dengan versi skema yang tidak
+// dapat dinaikkan ke versi saat ini.
+var ErrUnsupportedSchemaVersion = errors.New("unsupported schema version")
+
+// Upcaster mengubah event mentah dari versi N ke versi N+1 secara in-place.
+type Upcaster func(event map[string]interface{}) error
+
+// Registry menyimpan upcaster per versi asal.
+type Registry struct {
+	target    int
+	upcasters map[int]Upcaster
+}
+
+func NewRegistry(target int) *Registry {
+	return &Registry{target: target, upcasters: make(map[int]Upcaster)}
+}
+
+// DefaultRegistry mengembalikan registry untuk model.CurrentSchemaVersion dengan semua
+// upcaster bawaan.
+func DefaultRegistry() *Registry {
+	r := NewRegistry(model.CurrentSchemaVersion)
+	r.Register(model.SchemaVersionV1, upcastV1ToV2)
+	return r
+}
+
+// Register mendaftarkan upcaster dari versi from ke from+1.
+func (r *Registry) Register(from int, upcaster Upcaster) {
+	r.upcasters[from] = upcaster
+}
+
+// Versions mengembalikan versi skema yang diterima, dari yang terlama.
+func (r *Registry) Versions() []int {
+	oldest := r.target
+	for oldest > 1 && r.upcasters[oldest-1] != nil {
+		oldest--
+	}
+	versions := make([]int, 0, r.target-oldest+1)
+	for v := oldest; v <= r.target; v++ {
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// Decode membaca body pesan, menaikkan skemanya ke versi target, lalu memvalidasinya.
+// Event tanpa schema_version dianggap v1.
+func (r *Registry) Decode(body []byte) (model.NewsEvent, error) {
+	raw, err := parseObject(body)
+	if err != nil {
+		return model.NewsEvent{}, fmt.Errorf("failed to parse event: %w", err)
+	}
+	return r.decodeRaw(raw)
+}
+
+// decodeRaw menaikkan skema event mentah ke versi target lalu memvalidasinya.
+func (r *Registry) decodeRaw(raw map[string]interface{}) (model.NewsEvent, error) {
+	var event model.NewsEvent
+
+	version, err := schemaVersion(raw)
+	if err != nil {
+		return event, err
+	}
+	if version > r.target {
+		return event, fmt.Errorf("%w: %d (newest supported is %d)", ErrUnsupportedSchemaVersion, version, r.target)
+	}
+	for ; version < r.target; version++ {
+		upcaster, ok := r.upcasters[version]
+		if !ok {
+			return event, fmt.Errorf("%w: %d (no upcaster registered)", ErrUnsupportedSchemaVersion, version)
+		}
+		if err := upcaster(raw); err != nil {
+			return event, fmt.Errorf("failed to upcast event from schema v%d: %w", version, err)
+		}
+		raw["schema_version"] = version + 1
+	}
+
+	upcasted, err := json.Marshal(raw)
+	if err != nil {
+		return event, fmt.Errorf("failed to encode upcasted event: %w", err)
+	}
+	if err := json.Unmarshal(upcasted, &event); err != nil {
+		return event, fmt.Errorf("failed to parse event: %w", err)
+	}
+	if err := event.Validate(); err != nil {
+		return event, err
+	}
+	return event, nil
+}
+
+// parseObject mendekode objek JSON dengan angka sebagai json.Number agar version
+// (nanodetik) tidak kehilangan presisi.
+func parseObject(body []byte) (map[string]interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, errors.New("event must be a JSON object")
+	}
+	return raw, nil
+}
+
+func schemaVersion(raw map[string]interface{}) (int, error) {
+	value, ok := raw["schema_version"]
+	if !ok || value == nil {
+		return model.SchemaVersionV1, nil
+	}
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("schema_version must be a number")
+	}
+	version, err := number.Int64()
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedSchemaVersion, number)
+	}
+	return int(version), nil
+}
@@ -0,0 +1,181 @@
+package eventschema
+
+import (
+	"encoding/json"
+	"errors"
+	"search_service/pkg/model"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	occurredAt := time.Date(2024, 3, 1, 8, 30, 0, 123000000, time.UTC)
+	tests := []struct {
+		name string
+		body string
+		want model.NewsEvent
+	}{
+		{
+			name: "v2 event",
+			body: `{"schema_version":2,"event_id":"e1","type":"CREATED","occurred_at":"2024-03-01T08:30:00.123Z",` +
+				`"version":1709281800123000000,"payload":{"id":"a1","title":"Pemilu"}}`,
+			want: model.NewsEvent{
+				SchemaVersion: 2, EventID: "e1", Type: model.EventCreated, OccurredAt: occurredAt,
+				Version: 1709281800123000000, Payload: model.DocumentNews{ID: "a1", Title: "Pemilu"},
+			},
+		},
+		{
+			name: "v1 event without schema_version is upcast",
+			body: `{"event_id":"e1","type":"DELETED","timestamp":"2024-03-01T08:30:00.123Z","payload":{"id":"a1"}}`,
+			want: model.NewsEvent{
+				SchemaVersion: 2, EventID: "e1", Type: model.EventDeleted, OccurredAt: occurredAt,
+				Version: occurredAt.UnixNano(), Payload: model.DocumentNews{ID: "a1"},
+			},
+		},
+		{
+			name: "v1 event keeps explicit version",
+			body: `{"schema_version":1,"event_id":"e1","type":"DELETED","timestamp":"2024-03-01T08:30:00.123Z",` +
+				`"version":42,"payload":{"id":"a1"}}`,
+			want: model.NewsEvent{
+				SchemaVersion: 2, EventID: "e1", Type: model.EventDeleted, OccurredAt: occurredAt,
+				Version: 42, Payload: model.DocumentNews{ID: "a1"},
+			},
+		},
+		{
+			name: "v2 partial update",
+			body: `{"schema_version":2,"event_id":"e2","type":"UPDATED","occurred_at":"2024-03-01T08:30:00.123Z",` +
+				`"version":5,"update_mask":["tags"],"payload":{"id":"a1","tags":["x"]}}`,
+			want: model.NewsEvent{
+				SchemaVersion: 2, EventID: "e2", Type: model.EventUpdated, OccurredAt: occurredAt,
+				Version: 5, UpdateMask: []string{"tags"}, Payload: model.DocumentNews{ID: "a1", Tags: []string{"x"}},
+			},
+		},
+	}
+
+	registry := DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := registry.Decode([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("Decode returned error: %v", err)
+			}
+			assertEvent(t, got, tt.want)
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		unsupported bool
+		contains    string
+	}{
+		{name: "not json", body: `{`, contains: "failed to parse event"},
+		{name: "not an object", body: `null`, contains: "must be a JSON object"},
+		{name: "schema version is a string", body: `{"schema_version":"2"}`, contains: "must be a number"},
+		{name: "schema version zero", body: `{"schema_version":0}`, unsupported: true},
+		{name: "schema version from the future", body: `{"schema_version":3}`, unsupported: true},
+		{name: "invalid v1 timestamp", body: `{"type":"DELETED","timestamp":"yesterday","payload":{"id":"a1"}}`, contains: "invalid timestamp"},
+		{
+			name:     "v1 without timestamp fails validation",
+			body:     `{"event_id":"e1","type":"DELETED","payload":{"id":"a1"}}`,
+			contains: "occurred_at is required",
+		},
+		{
+			name: "v2 without event_id fails validation",
+			body: `{"schema_version":2,"type":"DELETED","occurred_at":"2024-03-01T08:30:00Z","version":1,` +
+				`"payload":{"id":"a1"}}`,
+			contains: "event_id is required",
+		},
+		{
+			name: "unknown update_mask field",
+			body: `{"schema_version":2,"event_id":"e1","type":"UPDATED","occurred_at":"2024-03-01T08:30:00Z",` +
+				`"version":1,"update_mask":["id"],"payload":{"id":"a1"}}`,
+			contains: "update_mask contains unknown field 'id'",
+		},
+	}
+
+	registry := DefaultRegistry()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Decode([]byte(tt.body))
+			if err == nil {
+				t.Fatalf("Decode(%s) succeeded, want error", tt.body)
+			}
+			if tt.unsupported && !errors.Is(err, ErrUnsupportedSchemaVersion) {
+				t.Errorf("Decode(%s) error = %v, want ErrUnsupportedSchemaVersion", tt.body, err)
+			}
+			if !strings.Contains(err.Error(), tt.contains) {
+				t.Errorf("Decode(%s) error = %q, want it to contain %q", tt.body, err, tt.contains)
+			}
+		})
+	}
+}
+
+func TestUpcastV1ToV2(t *testing.T) {
+	const body = `{"type":"CREATED","timestamp":"2024-03-01T08:30:00Z","payload":{"id":"a1","title":"Pemilu"}}`
+	upcast := func() map[string]interface{} {
+		raw, err := parseObject([]byte(body))
+		if err != nil {
+			t.Fatalf("parseObject returned error: %v", err)
+		}
+		if err := upcastV1ToV2(raw); err != nil {
+			t.Fatalf("upcastV1ToV2 returned error: %v", err)
+		}
+		return raw
+	}
+
+	first := upcast()
+	if _, ok := first["timestamp"]; ok {
+		t.Error("timestamp was not removed")
+	}
+	if got := first["occurred_at"]; got != "2024-03-01T08:30:00Z" {
+		t.Errorf("occurred_at = %v, want the v1 timestamp", got)
+	}
+	if got, want := first["version"], time.Date(2024, 3, 1, 8, 30, 0, 0, time.UTC).UnixNano(); got != want {
+		t.Errorf("version = %v, want %d", got, want)
+	}
+	eventID, _ := first["event_id"].(string)
+	if !strings.HasPrefix(eventID, "v1-") || len(eventID) != len("v1-")+32 {
+		t.Errorf("event_id = %q, want 'v1-' followed by 32 hex characters", eventID)
+	}
+
+	// Pengiriman ulang event v1 yang sama harus menghasilkan event_id yang sama.
+	if second := upcast(); second["event_id"] != eventID {
+		t.Errorf("event_id of redelivered event = %v, want %q", second["event_id"], eventID)
+	}
+
+	other, _ := parseObject([]byte(strings.Replace(body, "Pemilu", "Pilkada", 1)))
+	if err := upcastV1ToV2(other); err != nil {
+		t.Fatalf("upcastV1ToV2 returned error: %v", err)
+	}
+	if other["event_id"] == eventID {
+		t.Error("different v1 events got the same event_id")
+	}
+}
+
+func TestRegistryVersions(t *testing.T) {
+	if got, want := DefaultRegistry().Versions(), []int{1, 2}; !slices.Equal(got, want) {
+		t.Errorf("DefaultRegistry().Versions() = %v, want %v", got, want)
+	}
+	if got, want := NewRegistry(2).Versions(), []int{2}; !slices.Equal(got, want) {
+		t.Errorf("NewRegistry(2).Versions() = %v, want %v", got, want)
+	}
+
+	_, err := NewRegistry(2).Decode([]byte(`{"schema_version":1}`))
+	if !errors.Is(err, ErrUnsupportedSchemaVersion) {
+		t.Errorf("Decode of v1 without upcaster error = %v, want ErrUnsupportedSchemaVersion", err)
+	}
+}
+
+func assertEvent(t *testing.T, got, want model.NewsEvent) {
+	t.Helper()
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if string(gotJSON) != string(wantJSON) {
+		t.Errorf("decoded event\n got: %s\nwant: %s", gotJSON, wantJSON)
+	}
+}
@@ -0,0 +1,39 @@
+package eventschema
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// upcastV1ToV2 mengganti "timestamp" menjadi "occurred_at" dan melengkapi field yang
+// wajib di v2: version diturunkan dari timestamp, dan event_id dari hash isi event
+// sehingga pengiriman ulang event v1 yang sama tetap menghasilkan ID yang sama.
+func upcastV1ToV2(event map[string]interface{}) error {
+	if _, ok := event["event_id"]; !ok || event["event_id"] == "" {
+		canonical, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to hash v1 event: %w", err)
+		}
+		sum := sha256.Sum256(canonical)
+		event["event_id"] = "v1-" + hex.EncodeToString(sum[:16])
+	}
+
+	timestamp, _ := event["timestamp"].(string)
+	delete(event, "timestamp")
+	if timestamp == "" {
+		return nil // Validasi akan melaporkan occurred_at yang kosong
+	}
+	occurredAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp '%s': %w", timestamp, err)
+	}
+	event["occurred_at"] = timestamp
+
+	if version, ok := event["version"].(json.Number); !ok || version.String() == "0" {
+		event["version"] = occurredAt.UnixNano()
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -58,6 +59,11 @@ func (h *AdminHandler) CreateIndex(w http.ResponseWriter, r *http.Request) {
 		util.SendErrorResponse(w, http.StatusBadRequest, "Failed to read request body", err.Error())
 		return
 	}
+	// Tanpa body, indeks dibuat dengan mapping default agar sub-field untuk suggest
+	// dan spellcheck tetap ada.
+	if len(bytes.TrimSpace(bodyBytes)) == 0 {
+		bodyBytes = []byte(repository.NewsIndexMapping)
+	}
 	if !json.Valid(bodyBytes) {
 		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid JSON in request body", nil)
 		return
@@ -97,6 +103,9 @@ func (h *AdminHandler) CreateIndex(w http.ResponseWriter, r *http.Request) {
 		util.SendErrorResponse(w, http.StatusInternalServerError, "Error response from Elasticsearch when creating index", createRes.String())
 		return
 	}
+	if err := h.ESRepo.RefreshIndexCapabilities(context.Background(), indexName); err != nil {
+		log.Printf("Failed to refresh capabilities of index '%s': %v", indexName, err)
+	}
 
 	util.SendSuccessResponse(w, http.StatusCreated, fmt.Sprintf("Index '%s' created successfully", indexName), nil)
 }
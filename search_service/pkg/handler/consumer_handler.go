@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"net/http"
+	"search_service/pkg/consumer"
+	"search_service/pkg/util"
+)
+
+type ConsumerHandler struct {
+	Consumer *consumer.RabbitMQConsumer
+}
+
+func NewConsumerHandler(rmqConsumer *consumer.RabbitMQConsumer) *ConsumerHandler {
+	return &ConsumerHandler{Consumer: rmqConsumer}
+}
+
+// GetStats menghandle request GET /admin/consumer/stats: status koneksi consumer serta
+// jumlah event_id yang diingat dan pengiriman duplikat yang dilewati.
+func (h *ConsumerHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	util.SendSuccessResponse(w, http.StatusOK, "Consumer stats retrieved successfully", map[string]interface{}{
+		"state":  h.Consumer.State(),
+		"dedupe": h.Consumer.DedupeStats(),
+	})
+}
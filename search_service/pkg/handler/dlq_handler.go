@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"search_service/pkg/consumer"
+	"search_service/pkg/util"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type DLQHandler struct {
+	DeadLetters *consumer.DeadLetterManager
+}
+
+func NewDLQHandler(deadLetters *consumer.DeadLetterManager) *DLQHandler {
+	return &DLQHandler{
+		DeadLetters: deadLetters,
+	}
+}
+
+// ListMessages menghandle request GET /admin/dlq
+func (h *DLQHandler) ListMessages(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	messages, total, err := h.DeadLetters.List(limit)
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to list dead-letter messages", err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"total":    total,
+		"limit":    limit,
+		"messages": messages,
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Dead-letter messages retrieved successfully", response)
+}
+
+// GetMessage menghandle request GET /admin/dlq/{id}
+func (h *DLQHandler) GetMessage(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	msg, err := h.DeadLetters.Get(id)
+	if errors.Is(err, consumer.ErrDeadLetterNotFound) {
+		util.SendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Dead-letter message '%s' not found", id), nil)
+		return
+	}
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve dead-letter message", err.Error())
+		return
+	}
+
+	util.SendSuccessResponse(w, http.StatusOK, "Dead-letter message retrieved successfully", msg)
+}
+
+// ReplayMessage menghandle request POST /admin/dlq/{id}/replay.
+// Body opsional: {"patch": {...}} berupa JSON Merge Patch untuk payload event.
+func (h *DLQHandler) ReplayMessage(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	bodyBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Failed to read request body", err.Error())
+		return
+	}
+
+	var req struct {
+		Patch json.RawMessage `json:"patch"`
+	}
+	if len(bodyBytes) > 0 {
+		if err := json.Unmarshal(bodyBytes, &req); err != nil {
+			util.SendErrorResponse(w, http.StatusBadRequest, "Invalid JSON in request body", err.Error())
+			return
+		}
+	}
+	// Merge patch selain object (mis. null) akan mengganti seluruh payload.
+	if len(req.Patch) > 0 && !bytes.HasPrefix(bytes.TrimSpace(req.Patch), []byte("{")) {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Field 'patch' must be a JSON object", string(req.Patch))
+		return
+	}
+
+	err = h.DeadLetters.Replay(context.Background(), id, req.Patch)
+	if errors.Is(err, consumer.ErrDeadLetterNotFound) {
+		util.SendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Dead-letter message '%s' not found", id), nil)
+		return
+	}
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to replay dead-letter message", err.Error())
+		return
+	}
+
+	util.SendSuccessResponse(w, http.StatusOK, fmt.Sprintf("Dead-letter message '%s' replayed successfully", id), nil)
+}
+
+// ReplayAll menghandle request POST /admin/dlq/replay
+func (h *DLQHandler) ReplayAll(w http.ResponseWriter, r *http.Request) {
+	count, err := h.DeadLetters.ReplayAll(context.Background())
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to replay dead-letter messages", map[string]interface{}{
+			"error":    err.Error(),
+			"replayed": count,
+		})
+		return
+	}
+
+	util.SendSuccessResponse(w, http.StatusOK, "Dead-letter messages replayed successfully", map[string]interface{}{"replayed": count})
+}
+
+// DeleteMessage menghandle request DELETE /admin/dlq/{id}
+func (h *DLQHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	err := h.DeadLetters.Delete(id)
+	if errors.Is(err, consumer.ErrDeadLetterNotFound) {
+		util.SendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("Dead-letter message '%s' not found", id), nil)
+		return
+	}
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to delete dead-letter message", err.Error())
+		return
+	}
+
+	util.SendSuccessResponse(w, http.StatusOK, fmt.Sprintf("Dead-letter message '%s' deleted successfully", id), nil)
+}
+
+// PurgeMessages menghandle request DELETE /admin/dlq
+func (h *DLQHandler) PurgeMessages(w http.ResponseWriter, r *http.Request) {
+	count, err := h.DeadLetters.Purge()
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to purge dead-letter queue", err.Error())
+		return
+	}
+
+	util.SendSuccessResponse(w, http.StatusOK, "Dead-letter queue purged successfully", map[string]interface{}{"purged": count})
+}
@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"net/http"
+	"search_service/pkg/consumer"
+	"search_service/pkg/repository"
+	"search_service/pkg/util"
+)
+
+type HealthHandler struct {
+	ESRepo   *repository.ElasticSearchRepository
+	Consumer *consumer.RabbitMQConsumer
+}
+
+func NewHealthHandler(esRepo *repository.ElasticSearchRepository, rmqConsumer *consumer.RabbitMQConsumer) *HealthHandler {
+	return &HealthHandler{
+		ESRepo:   esRepo,
+		Consumer: rmqConsumer,
+	}
+}
+
+// GetHealth menghandle request GET /admin/health. Mengembalikan 503 jika Elasticsearch
+// tidak dapat dijangkau atau consumer RabbitMQ sedang tidak terhubung, sehingga data
+// index yang mulai basi tidak terlihat sehat.
+func (h *HealthHandler) GetHealth(w http.ResponseWriter, r *http.Request) {
+	status := map[string]interface{}{
+		"elasticsearch": "up",
+		"rabbitmq":      h.Consumer.State(),
+	}
+	healthy := h.Consumer.State() == consumer.StateConnected
+
+	if err := h.ESRepo.Ping(); err != nil {
+		status["elasticsearch"] = "down"
+		status["elasticsearch_error"] = err.Error()
+		healthy = false
+	}
+
+	if !healthy {
+		util.SendErrorResponse(w, http.StatusServiceUnavailable, "Service is unhealthy", status)
+		return
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Service is healthy", status)
+}
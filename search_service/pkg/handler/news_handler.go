@@ -3,11 +3,19 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"search_service/pkg/model"
+	"search_service/pkg/querylang"
+	"search_service/pkg/repository"
 	"search_service/pkg/service"
 	"search_service/pkg/util"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
@@ -24,34 +32,317 @@ func NewNewsHandler(newsService *service.NewsService) *NewsHandler {
 
 // SearchNews menghandle request GET /news
 func (h *NewsHandler) SearchNews(w http.ResponseWriter, r *http.Request) {
-	query := r.URL.Query().Get("q")
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
+	searchReq, err := parseSearchRequest(r.URL.Query())
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid search parameters", err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	result, err := h.NewsService.SearchNewsArticles(ctx, searchReq)
+	var syntaxErr *querylang.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid search query syntax", syntaxErr)
+		return
+	}
+	if errors.Is(err, repository.ErrPointInTimeNotFound) {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Search cursor has expired, start a new search", err.Error())
+		return
+	}
+	if errors.Is(err, model.ErrCursorMismatch) {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid search parameters", err.Error())
+		return
+	}
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to search news articles", err.Error())
+		return
+	}
+
+	response := map[string]interface{}{
+		"total_hits": result.TotalHits,
+		"limit":      searchReq.Limit,
+		"sort":       searchReq.Sort.Field + ":" + searchReq.Sort.Order,
+		"articles":   result.Articles,
+	}
+	if searchReq.UseCursor {
+		response["next_cursor"] = result.NextCursor
+	} else {
+		response["page"] = searchReq.Page
+	}
+	if result.Facets != nil {
+		response["facets"] = result.Facets
+	}
+	if result.DidYouMean != "" {
+		response["did_you_mean"] = result.DidYouMean
+	}
+	if result.ExecutedQuery != searchReq.Query {
+		response["executed_query"] = result.ExecutedQuery
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "News articles retrieved successfully", response)
+}
+
+// RelatedNews menghandle request GET /news/{id}/related
+func (h *NewsHandler) RelatedNews(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if id == "" {
+		util.SendErrorResponse(w, http.StatusBadRequest, "News ID is required", nil)
+		return
+	}
 
-	page, err := strconv.Atoi(pageStr)
+	searchReq, err := parseSearchRequest(r.URL.Query())
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Invalid search parameters", err.Error())
+		return
+	}
+
+	ctx := context.Background()
+	result, err := h.NewsService.RelatedNewsArticles(ctx, id, searchReq)
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve related news articles", err.Error())
+		return
+	}
+
+	if result == nil {
+		util.SendErrorResponse(w, http.StatusNotFound, fmt.Sprintf("News article with ID '%s' not found", id), nil)
+		return
+	}
+
+	response := map[string]interface{}{
+		"total_hits": result.TotalHits,
+		"page":       searchReq.Page,
+		"limit":      searchReq.Limit,
+		"articles":   result.Articles,
+	}
+	if result.Facets != nil {
+		response["facets"] = result.Facets
+	}
+	util.SendSuccessResponse(w, http.StatusOK, "Related news articles retrieved successfully", response)
+}
+
+// SuggestNews menghandle request GET /news/suggest
+func (h *NewsHandler) SuggestNews(w http.ResponseWriter, r *http.Request) {
+	prefix := strings.TrimSpace(r.URL.Query().Get("q"))
+	if prefix == "" {
+		util.SendErrorResponse(w, http.StatusBadRequest, "Query parameter 'q' is required", nil)
+		return
+	}
+
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 5
+	}
+	if limit > 20 {
+		limit = 20
+	}
+
+	ctx := context.Background()
+	result, err := h.NewsService.SuggestNews(ctx, prefix, limit)
+	if err != nil {
+		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to get news suggestions", err.Error())
+		return
+	}
+
+	util.SendSuccessResponse(w, http.StatusOK, "News suggestions retrieved successfully", result)
+}
+
+// parseSearchRequest membaca query string GET /news menjadi model.SearchRequest.
+func parseSearchRequest(values url.Values) (model.SearchRequest, error) {
+	page, err := strconv.Atoi(values.Get("page"))
 	if err != nil || page < 1 {
 		page = 1
 	}
 
-	limit, err := strconv.Atoi(limitStr)
+	limit, err := strconv.Atoi(values.Get("limit"))
 	if err != nil || limit < 1 {
 		limit = 10
 	}
 
-	ctx := context.Background()
-	news, total, err := h.NewsService.SearchNewsArticles(ctx, query, page, limit)
+	req := model.SearchRequest{
+		Query:       values.Get("q"),
+		Page:        page,
+		Limit:       limit,
+		Tags:        nonEmptyValues(values["tag"]),
+		ExcludeTags: nonEmptyValues(values["exclude_tag"]),
+		Author:      strings.TrimSpace(values.Get("author")),
+	}
+
+	switch tagMode := strings.ToLower(values.Get("tag_mode")); tagMode {
+	case "", model.TagModeAny:
+		req.TagMode = model.TagModeAny
+	case model.TagModeAll:
+		req.TagMode = model.TagModeAll
+	default:
+		return req, fmt.Errorf("tag_mode must be '%s' or '%s', got '%s'", model.TagModeAny, model.TagModeAll, tagMode)
+	}
+
+	// cursor kosong (atau "*") memulai penelusuran baru; nilainya diisi dari next_cursor.
+	if values.Has("cursor") {
+		req.UseCursor = true
+		if raw := values.Get("cursor"); raw != "" && raw != "*" {
+			if req.Cursor, err = model.DecodeCursor(raw); err != nil {
+				return req, err
+			}
+		}
+	}
+
+	sortOption, err := model.ParseSort(values.Get("sort"))
 	if err != nil {
-		util.SendErrorResponse(w, http.StatusInternalServerError, "Failed to search news articles", err.Error())
-		return
+		return req, err
 	}
+	req.Sort = sortOption
 
-	response := map[string]interface{}{
-		"total_hits": total,
-		"page":       page,
-		"limit":      limit,
-		"articles":   news,
+	facetSize, err := strconv.Atoi(values.Get("facet_size"))
+	if err != nil || facetSize < 1 {
+		facetSize = model.DefaultFacetSize
 	}
-	util.SendSuccessResponse(w, http.StatusOK, "News articles retrieved successfully", response)
+	facets, err := model.ParseFacets(values.Get("facets"), facetSize)
+	if err != nil {
+		return req, err
+	}
+	req.Facets = facets
+
+	switch facetMode := strings.ToLower(values.Get("facet_mode")); facetMode {
+	case "", "standard":
+	case "multi_select":
+		req.MultiSelectFacets = true
+	default:
+		return req, fmt.Errorf("facet_mode must be 'standard' or 'multi_select', got '%s'", facetMode)
+	}
+
+	if req.AutoCorrect, err = parseBoolParam(values, "auto_correct"); err != nil {
+		return req, err
+	}
+
+	highlight, err := parseHighlightOptions(values)
+	if err != nil {
+		return req, err
+	}
+	req.Highlight = highlight
+
+	if from := values.Get("published_from"); from != "" {
+		t, err := parseDateParam(from, false)
+		if err != nil {
+			return req, fmt.Errorf("invalid published_from: %w", err)
+		}
+		req.PublishedFrom = &t
+	}
+	if to := values.Get("published_to"); to != "" {
+		t, err := parseDateParam(to, true)
+		if err != nil {
+			return req, fmt.Errorf("invalid published_to: %w", err)
+		}
+		req.PublishedTo = &t
+	}
+	if req.PublishedFrom != nil && req.PublishedTo != nil && req.PublishedFrom.After(*req.PublishedTo) {
+		return req, fmt.Errorf("published_from must not be after published_to")
+	}
+
+	return req, nil
+}
+
+// parseHighlightOptions membaca parameter highlight, snippet, fragment_size, fragments,
+// pre_tag dan post_tag. Mode snippet otomatis mengaktifkan highlight.
+func parseHighlightOptions(values url.Values) (model.HighlightOptions, error) {
+	opt := model.HighlightOptions{
+		FragmentSize:      model.DefaultFragmentSize,
+		NumberOfFragments: model.DefaultNumberOfFragments,
+		PreTag:            model.DefaultHighlightPreTag,
+		PostTag:           model.DefaultHighlightPostTag,
+	}
+
+	var err error
+	if opt.Enabled, err = parseBoolParam(values, "highlight"); err != nil {
+		return opt, err
+	}
+	if opt.Snippet, err = parseBoolParam(values, "snippet"); err != nil {
+		return opt, err
+	}
+	opt.Enabled = opt.Enabled || opt.Snippet
+
+	if v := values.Get("fragment_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 20 || size > 1000 {
+			return opt, fmt.Errorf("fragment_size must be a number between 20 and 1000")
+		}
+		opt.FragmentSize = size
+	}
+	if v := values.Get("fragments"); v != "" {
+		count, err := strconv.Atoi(v)
+		if err != nil || count < 1 || count > 10 {
+			return opt, fmt.Errorf("fragments must be a number between 1 and 10")
+		}
+		opt.NumberOfFragments = count
+	}
+	tag, err := parseHighlightTag(values)
+	if err != nil {
+		return opt, err
+	}
+	opt.PreTag = "<" + tag + ">"
+	opt.PostTag = "</" + tag + ">"
+	return opt, nil
+}
+
+// parseHighlightTag membaca pre_tag dan post_tag. Keduanya hanya boleh berupa nama tag
+// dari model.HighlightTags (mis. "mark", "<mark>" atau "</mark>") dan harus sama;
+// nilai lain ditolak agar markup dari query string tidak masuk ke respons.
+func parseHighlightTag(values url.Values) (string, error) {
+	tag := ""
+	for _, key := range []string{"pre_tag", "post_tag"} {
+		v := strings.ToLower(strings.TrimSpace(values.Get(key)))
+		if v == "" {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(v, "</"), "<"), ">")
+		if !slices.Contains(model.HighlightTags, name) {
+			return "", fmt.Errorf("%s must be one of %s, got '%s'", key, strings.Join(model.HighlightTags, ", "), values.Get(key))
+		}
+		if tag != "" && tag != name {
+			return "", fmt.Errorf("pre_tag and post_tag must use the same tag, got '%s' and '%s'", tag, name)
+		}
+		tag = name
+	}
+	if tag == "" {
+		tag = model.DefaultHighlightTag
+	}
+	return tag, nil
+}
+
+func parseBoolParam(values url.Values, key string) (bool, error) {
+	v := values.Get(key)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be true or false, got '%s'", key, v)
+	}
+	return b, nil
+}
+
+// parseDateParam menerima RFC3339 atau tanggal saja (YYYY-MM-DD).
+// Untuk batas akhir, tanggal saja dianggap inklusif sampai akhir hari tersebut.
+func parseDateParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%s' is not a valid date (use YYYY-MM-DD or RFC3339)", value)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Millisecond)
+	}
+	return t, nil
+}
+
+func nonEmptyValues(values []string) []string {
+	var result []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
 }
 
 func (h *NewsHandler) GetNewsByID(w http.ResponseWriter, r *http.Request) {
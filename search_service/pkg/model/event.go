@@ -2,8 +2,52 @@ package model
 
 import "time"
 
+// Jenis event berita.
+const (
+	EventCreated = "CREATED"
+	EventUpdated = "UPDATED"
+	EventDeleted = "DELETED"
+)
+
+// Versi skema NewsEvent. v1 memakai "timestamp" dan event_id/version opsional; v2
+// memakai "occurred_at" dan mewajibkan event_id serta version. Event v1 dinaikkan ke
+// v2 oleh upcaster di package eventschema sebelum diproses.
+const (
+	SchemaVersionV1      = 1
+	SchemaVersionV2      = 2
+	CurrentSchemaVersion = SchemaVersionV2
+)
+
 type NewsEvent struct {
-	Type      string       `json:"type"`
-	Timestamp time.Time    `json:"timestamp"`
-	Payload   DocumentNews `json:"payload"`
+	SchemaVersion int `json:"schema_version"`
+	// EventID unik per event (bukan per pengiriman); dipakai untuk deduplikasi.
+	EventID    string    `json:"event_id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	// Version naik secara monoton untuk setiap perubahan artikel dan dipakai sebagai
+	// external version Elasticsearch.
+	Version int64        `json:"version"`
+	Payload DocumentNews `json:"payload"`
+	// Source dan Subject berasal dari atribut CloudEvents (subject adalah ID artikel);
+	// kosong untuk event format lama.
+	Source  string `json:"source,omitempty"`
+	Subject string `json:"subject,omitempty"`
+	// UpdateMask berisi nama field JSON Payload yang diubah oleh event UPDATED. Jika
+	// kosong, Payload dianggap dokumen lengkap; jika diisi, hanya field tersebut yang
+	// diterapkan dan field lain pada dokumen tetap.
+	UpdateMask []string `json:"update_mask,omitempty"`
+}
+
+// IsPartialUpdate melaporkan apakah event adalah UPDATED dengan field mask.
+func (e NewsEvent) IsPartialUpdate() bool {
+	return e.Type == EventUpdated && len(e.UpdateMask) > 0
+}
+
+// DedupeKey mengembalikan kunci deduplikasi event. Sesuai CloudEvents, id hanya unik
+// dalam satu source sehingga source ikut menjadi bagian kunci jika ada.
+func (e NewsEvent) DedupeKey() string {
+	if e.EventID == "" || e.Source == "" {
+		return e.EventID
+	}
+	return e.Source + "|" + e.EventID
 }
@@ -8,6 +8,7 @@ type DocumentNews struct {
 	Content     string     `json:"content"`
 	Author      string     `json:"author"`
 	Tags        []string   `json:"tags"`
+	Category    string     `json:"category,omitempty"`
 	PublishedAt time.Time  `json:"published_at"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
@@ -0,0 +1,271 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"search_service/pkg/querylang"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrCursorMismatch dikembalikan jika cursor dipakai dengan query, sort atau filter
+// yang berbeda dari pencarian yang menghasilkannya.
+var ErrCursorMismatch = errors.New("cursor does not match the search parameters")
+
+const (
+	TagModeAny = "any"
+	TagModeAll = "all"
+)
+
+const (
+	SortRelevance   = "relevance"
+	SortPublishedAt = "published_at"
+	SortCreatedAt   = "created_at"
+	SortUpdatedAt   = "updated_at"
+
+	SortAsc  = "asc"
+	SortDesc = "desc"
+)
+
+const (
+	FacetTags        = "tags"
+	FacetAuthor      = "author"
+	FacetPublishedAt = "published_at"
+
+	DefaultFacetSize = 10
+)
+
+// FacetNames adalah daftar facet yang didukung, dalam urutan yang tetap.
+var FacetNames = []string{FacetTags, FacetAuthor, FacetPublishedAt}
+
+// FacetRequest meminta hitungan facet untuk satu field. Interval hanya dipakai oleh
+// facet published_at (day, week atau month).
+type FacetRequest struct {
+	Name     string
+	Interval string
+	Size     int
+}
+
+// ParseFacets membaca parameter facets, misalnya "tags,author,published_at:month".
+func ParseFacets(raw string, size int) ([]FacetRequest, error) {
+	if size < 1 {
+		size = DefaultFacetSize
+	}
+
+	var facets []FacetRequest
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		name, interval, hasInterval := strings.Cut(part, ":")
+		switch name {
+		case FacetTags, FacetAuthor:
+			if hasInterval {
+				return nil, fmt.Errorf("facet '%s' does not accept an interval", name)
+			}
+		case FacetPublishedAt:
+			if !hasInterval {
+				interval = "month"
+			}
+			if interval != "day" && interval != "week" && interval != "month" {
+				return nil, fmt.Errorf("unknown interval '%s' for facet '%s' (allowed: day, week, month)", interval, name)
+			}
+		default:
+			return nil, fmt.Errorf("unknown facet '%s' (allowed: %s)", name, strings.Join(FacetNames, ", "))
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("facet '%s' requested more than once", name)
+		}
+		seen[name] = true
+		facets = append(facets, FacetRequest{Name: name, Interval: interval, Size: size})
+	}
+	return facets, nil
+}
+
+const (
+	DefaultFragmentSize      = 150
+	DefaultNumberOfFragments = 3
+	DefaultHighlightTag      = "em"
+	DefaultHighlightPreTag   = "<" + DefaultHighlightTag + ">"
+	DefaultHighlightPostTag  = "</" + DefaultHighlightTag + ">"
+)
+
+// HighlightTags adalah tag HTML yang boleh dipakai untuk membungkus highlight. Tag
+// dibentuk di server karena Elasticsearch tidak meng-escape pre_tags/post_tags.
+var HighlightTags = []string{"em", "mark", "strong", "b"}
+
+// HighlightOptions mengatur highlight pada title dan content. Pada mode Snippet,
+// content tidak dikirim utuh melainkan diganti dengan potongan fragmen yang cocok.
+type HighlightOptions struct {
+	Enabled           bool
+	Snippet           bool
+	FragmentSize      int
+	NumberOfFragments int
+	PreTag            string
+	PostTag           string
+}
+
+// SortOption menentukan urutan hasil pencarian.
+type SortOption struct {
+	Field string
+	Order string
+}
+
+// ParseSort membaca parameter sort dengan format "field" atau "field:order",
+// misalnya "relevance", "published_at" atau "created_at:asc". Field tanggal default ke desc.
+func ParseSort(raw string) (SortOption, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return SortOption{Field: SortRelevance, Order: SortDesc}, nil
+	}
+
+	field, order, hasOrder := strings.Cut(raw, ":")
+	switch field {
+	case SortRelevance:
+		if hasOrder && order != SortDesc {
+			return SortOption{}, fmt.Errorf("sort '%s' only supports descending order", SortRelevance)
+		}
+		return SortOption{Field: SortRelevance, Order: SortDesc}, nil
+	case SortPublishedAt, SortCreatedAt, SortUpdatedAt:
+	default:
+		return SortOption{}, fmt.Errorf("unknown sort field '%s' (allowed: %s, %s, %s, %s)",
+			field, SortRelevance, SortPublishedAt, SortCreatedAt, SortUpdatedAt)
+	}
+
+	if !hasOrder {
+		order = SortDesc
+	}
+	if order != SortAsc && order != SortDesc {
+		return SortOption{}, fmt.Errorf("unknown sort order '%s' (allowed: %s, %s)", order, SortAsc, SortDesc)
+	}
+	return SortOption{Field: field, Order: order}, nil
+}
+
+// SearchRequest berisi parameter pencarian artikel berita beserta filternya.
+type SearchRequest struct {
+	Query string
+	// Expr adalah hasil parsing Query; diisi oleh service sebelum pencarian dijalankan.
+	Expr querylang.Node
+	// SpellcheckText adalah teks bebas dari Query yang dipakai untuk saran ejaan.
+	SpellcheckText string
+
+	Page          int
+	Limit         int
+	Tags          []string
+	TagMode       string // TagModeAny atau TagModeAll
+	ExcludeTags   []string
+	Author        string
+	PublishedFrom *time.Time
+	PublishedTo   *time.Time
+	Sort          SortOption
+
+	Facets []FacetRequest
+	// MultiSelectFacets membuat setiap facet mengabaikan filternya sendiri.
+	MultiSelectFacets bool
+
+	Highlight HighlightOptions
+
+	// RelatedTo berisi ID artikel sumber untuk pencarian artikel terkait (more-like-this).
+	RelatedTo string
+
+	// UseCursor mengaktifkan pagination berbasis cursor (point-in-time + search_after).
+	// Cursor bernilai nil berarti halaman pertama dan point-in-time baru akan dibuka.
+	UseCursor       bool
+	Cursor          *SearchCursor
+	CursorKeepAlive string
+
+	// AutoCorrect menjalankan ulang pencarian dengan query hasil koreksi ejaan.
+	AutoCorrect bool
+}
+
+// SearchResult adalah hasil pencarian beserta hitungan facet (jika diminta).
+type SearchResult struct {
+	TotalHits int64                    `json:"total_hits"`
+	Articles  []SearchHit              `json:"articles"`
+	Facets    map[string][]FacetBucket `json:"facets,omitempty"`
+
+	// DidYouMean berisi saran koreksi query jika hasil pencarian kosong atau sangat sedikit.
+	DidYouMean string `json:"did_you_mean,omitempty"`
+	// ExecutedQuery adalah query yang benar-benar dijalankan (berbeda jika auto-correct dipakai).
+	ExecutedQuery string `json:"executed_query,omitempty"`
+	// NextCursor kosong jika tidak ada halaman berikutnya.
+	NextCursor string `json:"next_cursor,omitempty"`
+
+	PitID    string        `json:"-"`
+	LastSort []interface{} `json:"-"`
+}
+
+// SearchCursor adalah isi cursor pagination sebelum di-encode menjadi string opaque.
+type SearchCursor struct {
+	PitID       string        `json:"pit"`
+	SearchAfter []interface{} `json:"after,omitempty"`
+	// QueryHash adalah hasil CursorQueryHash dari pencarian yang membuat cursor.
+	QueryHash string `json:"q,omitempty"`
+}
+
+func EncodeCursor(c SearchCursor) (string, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// CursorQueryHash meringkas query (dengan spasi dinormalisasi), sort dan filter pencarian.
+// Urutan tag tidak berpengaruh; limit, facet dan highlight boleh berubah antar halaman.
+func CursorQueryHash(req SearchRequest) string {
+	tags := slices.Sorted(slices.Values(req.Tags))
+	excludeTags := slices.Sorted(slices.Values(req.ExcludeTags))
+	data, _ := json.Marshal([]interface{}{
+		strings.Join(strings.Fields(req.Query), " "),
+		req.Sort.Field, req.Sort.Order,
+		tags, req.TagMode, excludeTags, req.Author,
+		req.PublishedFrom, req.PublishedTo,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:16])
+}
+
+func DecodeCursor(raw string) (*SearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is malformed")
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+	var c SearchCursor
+	if err := decoder.Decode(&c); err != nil || c.PitID == "" {
+		return nil, fmt.Errorf("cursor is malformed")
+	}
+	return &c, nil
+}
+
+// SearchHit adalah satu artikel hasil pencarian beserta fragmen highlight-nya.
+type SearchHit struct {
+	DocumentNews
+	Highlights map[string][]string `json:"highlights,omitempty"`
+}
+
+type FacetBucket struct {
+	Key   string `json:"key"`
+	Count int64  `json:"count"`
+}
+
+// SuggestResult berisi saran autocomplete untuk kotak pencarian.
+type SuggestResult struct {
+	Titles  []TitleSuggestion `json:"titles"`
+	Tags    []string          `json:"tags"`
+	Authors []string          `json:"authors"`
+}
+
+type TitleSuggestion struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
@@ -0,0 +1,117 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor SearchCursor
+	}{
+		{"first page", SearchCursor{PitID: "pit-1"}},
+		{
+			name: "relevance sort",
+			cursor: SearchCursor{
+				PitID:       "pit-2",
+				SearchAfter: []interface{}{json.Number("1.2345"), "article-7"},
+				QueryHash:   "abc",
+			},
+		},
+		{
+			name: "large date sort value keeps precision",
+			cursor: SearchCursor{
+				PitID:       "pit-3",
+				SearchAfter: []interface{}{json.Number("1717171717171"), json.Number("9007199254740993")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := EncodeCursor(tt.cursor)
+			if err != nil {
+				t.Fatalf("EncodeCursor returned error: %v", err)
+			}
+			got, err := DecodeCursor(raw)
+			if err != nil {
+				t.Fatalf("DecodeCursor(%q) returned error: %v", raw, err)
+			}
+			if !reflect.DeepEqual(*got, tt.cursor) {
+				t.Errorf("DecodeCursor(EncodeCursor(c)) = %#v, want %#v", *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"not base64", "%%%"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte(`{"pit":"p"}`))},
+		{"not json", encode("pit")},
+		{"missing pit", encode(`{"after":[1]}`)},
+		{"wrong type", encode(`{"pit":1}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := DecodeCursor(tt.raw); err == nil {
+				t.Errorf("DecodeCursor(%q) = %#v, want error", tt.raw, c)
+			}
+		})
+	}
+}
+
+func TestCursorQueryHash(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := SearchRequest{
+		Query:         "pemilu jakarta",
+		Tags:          []string{"politik", "nasional"},
+		TagMode:       TagModeAny,
+		Author:        "budi",
+		PublishedFrom: &from,
+		Sort:          SortOption{Field: SortPublishedAt, Order: SortDesc},
+		Limit:         10,
+	}
+	other := from.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		change func(*SearchRequest)
+		same   bool
+	}{
+		{"extra whitespace in query", func(r *SearchRequest) { r.Query = "  pemilu   jakarta " }, true},
+		{"tag order", func(r *SearchRequest) { r.Tags = []string{"nasional", "politik"} }, true},
+		{"limit", func(r *SearchRequest) { r.Limit = 50 }, true},
+		{"facets", func(r *SearchRequest) { r.Facets = []FacetRequest{{Name: FacetTags}} }, true},
+		{"query", func(r *SearchRequest) { r.Query = "pemilu" }, false},
+		{"sort field", func(r *SearchRequest) { r.Sort.Field = SortCreatedAt }, false},
+		{"sort order", func(r *SearchRequest) { r.Sort.Order = SortAsc }, false},
+		{"tags", func(r *SearchRequest) { r.Tags = []string{"politik"} }, false},
+		{"tag mode", func(r *SearchRequest) { r.TagMode = TagModeAll }, false},
+		{"excluded tags", func(r *SearchRequest) { r.ExcludeTags = []string{"hoaks"} }, false},
+		{"author", func(r *SearchRequest) { r.Author = "ani" }, false},
+		{"published from", func(r *SearchRequest) { r.PublishedFrom = &other }, false},
+		{"published to", func(r *SearchRequest) { r.PublishedTo = &other }, false},
+	}
+
+	want := CursorQueryHash(base)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			req.Tags = append([]string(nil), base.Tags...)
+			tt.change(&req)
+			if got := CursorQueryHash(req); (got == want) != tt.same {
+				t.Errorf("CursorQueryHash changed = %v, want changed = %v", got != want, !tt.same)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+// Package querylang mem-parsing sintaks query pencarian untuk pengguna menjadi AST.
+//
+// Sintaks yang didukung:
+//
+//	pemilu jakarta          kata biasa (semua harus cocok)
+//	"pemilu serentak"       frasa
+//	-hoaks  -"kata kunci"   pengecualian
+//	pemilu OR pilkada       alternatif
+//	(a OR b) c              pengelompokan
+//	author:budi  tag:politik  title:"pemilu 2024"
+//	published:>2024-01-01  published:<=2024-06-30  published:2024-03-01
+package querylang
+
+import "strings"
+
+// Field qualifier yang didukung.
+const (
+	FieldAuthor    = "author"
+	FieldTag       = "tag"
+	FieldTitle     = "title"
+	FieldPublished = "published"
+)
+
+// Node adalah elemen AST hasil parsing.
+type Node interface {
+	// Pos adalah posisi (1-based, dalam karakter) awal node pada input.
+	Pos() int
+}
+
+// TermNode adalah satu kata atau frasa, opsional dengan field qualifier.
+// Field kosong berarti teks bebas yang dicari di semua field teks.
+type TermNode struct {
+	Field  string
+	Value  string
+	Phrase bool
+	At     int
+}
+
+// RangeNode adalah perbandingan tanggal, misalnya published:>2024-01-01.
+// Op salah satu dari ">", ">=", "<", "<=" atau "=" (tanggal persis).
+type RangeNode struct {
+	Field string
+	Op    string
+	Value string
+	At    int
+}
+
+type NotNode struct {
+	Child Node
+	At    int
+}
+
+type AndNode struct {
+	Children []Node
+}
+
+type OrNode struct {
+	Children []Node
+}
+
+func (n *TermNode) Pos() int  { return n.At }
+func (n *RangeNode) Pos() int { return n.At }
+func (n *NotNode) Pos() int   { return n.At }
+func (n *AndNode) Pos() int   { return n.Children[0].Pos() }
+func (n *OrNode) Pos() int    { return n.Children[0].Pos() }
+
+// FreeText mengembalikan gabungan kata dan frasa bebas (tanpa field dan bukan pengecualian).
+// Dipakai sebagai teks untuk saran ejaan.
+func FreeText(n Node) string {
+	var parts []string
+	collectFreeText(n, &parts)
+	return strings.Join(parts, " ")
+}
+
+func collectFreeText(n Node, parts *[]string) {
+	switch node := n.(type) {
+	case *TermNode:
+		if node.Field == "" {
+			*parts = append(*parts, node.Value)
+		}
+	case *AndNode:
+		for _, child := range node.Children {
+			collectFreeText(child, parts)
+		}
+	case *OrNode:
+		for _, child := range node.Children {
+			collectFreeText(child, parts)
+		}
+	}
+}
+
+// IsPlainText bernilai true jika query hanya berisi kata bebas tanpa sintaks khusus.
+func IsPlainText(n Node) bool {
+	switch node := n.(type) {
+	case *TermNode:
+		return node.Field == "" && !node.Phrase
+	case *AndNode:
+		for _, child := range node.Children {
+			if !IsPlainText(child) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,128 @@
+package querylang
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokPhrase
+	tokField
+	tokMinus
+	tokLParen
+	tokRParen
+	tokOr
+	tokAnd
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // 1-based
+}
+
+func (t token) describe() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokPhrase:
+		return fmt.Sprintf("phrase %q", t.text)
+	case tokField:
+		return fmt.Sprintf("field '%s:'", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+// lex memecah input menjadi token. Posisi dihitung per karakter (rune), mulai dari 1.
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: pos})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, &SyntaxError{Position: pos, Message: "unterminated phrase, missing closing '\"'"}
+			}
+			text := string(runes[i+1 : end])
+			if strings.TrimSpace(text) == "" {
+				return nil, &SyntaxError{Position: pos, Message: "empty phrase"}
+			}
+			tokens = append(tokens, token{kind: tokPhrase, text: text, pos: pos})
+			i = end + 1
+		case r == '-' && isTermStart(runes, i) && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, token{kind: tokMinus, text: "-", pos: pos})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !isWordBoundary(runes[end]) {
+				if runes[end] == ':' && isIdentifier(runes[i:end]) {
+					break
+				}
+				end++
+			}
+			if end < len(runes) && runes[end] == ':' && end > i {
+				tokens = append(tokens, token{kind: tokField, text: string(runes[i:end]), pos: pos})
+				i = end + 1
+				continue
+			}
+			word := string(runes[i:end])
+			switch word {
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, text: word, pos: pos})
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, text: word, pos: pos})
+			default:
+				tokens = append(tokens, token{kind: tokWord, text: word, pos: pos})
+			}
+			i = end
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokEOF, pos: len(runes) + 1})
+	return tokens, nil
+}
+
+func isWordBoundary(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+// isTermStart bernilai true jika posisi i berada di awal sebuah term (bukan di tengah kata).
+func isTermStart(runes []rune, i int) bool {
+	return i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('
+}
+
+func isIdentifier(runes []rune) bool {
+	if len(runes) == 0 {
+		return false
+	}
+	for j, r := range runes {
+		if r == '_' || (r < utf8.RuneSelf && unicode.IsLetter(r)) || (j > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package querylang
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxDepth membatasi kedalaman tanda kurung agar input jahat tidak menghabiskan stack.
+const maxDepth = 16
+
+// SyntaxError menjelaskan kesalahan sintaks beserta posisinya (1-based, per karakter).
+type SyntaxError struct {
+	Position int    `json:"position"`
+	Message  string `json:"message"`
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+// Parse mem-parsing query menjadi AST. Query kosong menghasilkan nil, nil.
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, nil
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, p.errorAt(tok, "unexpected %s", tok.describe())
+	}
+	return node, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorAt(tok token, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Position: tok.pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// orExpr := andExpr ("OR" andExpr)*
+func (p *parser) parseOr(depth int) (Node, error) {
+	first, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{first}
+	for p.peek().kind == tokOr {
+		p.next()
+		if next := p.peek(); next.kind == tokEOF || next.kind == tokRParen || next.kind == tokOr || next.kind == tokAnd {
+			return nil, p.errorAt(next, "expected a search term after 'OR'")
+		}
+		child, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &OrNode{Children: children}, nil
+}
+
+// andExpr := unary (["AND"] unary)*
+func (p *parser) parseAnd(depth int) (Node, error) {
+	var children []Node
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(children) == 0 {
+				return nil, p.errorAt(tok, "expected a search term before %s", tok.describe())
+			}
+			if len(children) == 1 {
+				return children[0], nil
+			}
+			return &AndNode{Children: children}, nil
+		case tokAnd:
+			if len(children) == 0 {
+				return nil, p.errorAt(tok, "expected a search term before 'AND'")
+			}
+			p.next()
+			if next := p.peek(); next.kind == tokEOF || next.kind == tokRParen || next.kind == tokOr || next.kind == tokAnd {
+				return nil, p.errorAt(next, "expected a search term after 'AND'")
+			}
+		}
+
+		child, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+}
+
+// unary := "-" primary | primary
+func (p *parser) parseUnary(depth int) (Node, error) {
+	if tok := p.peek(); tok.kind == tokMinus {
+		p.next()
+		if next := p.peek(); next.kind == tokMinus {
+			return nil, p.errorAt(next, "double exclusion is not allowed")
+		}
+		child, err := p.parsePrimary(depth)
+		if err != nil {
+			return nil, err
+		}
+		return &NotNode{Child: child, At: tok.pos}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+// primary := "(" orExpr ")" | field value | WORD | PHRASE
+func (p *parser) parsePrimary(depth int) (Node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokWord:
+		return &TermNode{Value: tok.text, At: tok.pos}, nil
+	case tokPhrase:
+		return &TermNode{Value: tok.text, Phrase: true, At: tok.pos}, nil
+	case tokField:
+		return p.parseFieldValue(tok)
+	case tokLParen:
+		if depth+1 > maxDepth {
+			return nil, p.errorAt(tok, "too many nested parentheses (max %d)", maxDepth)
+		}
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokRParen {
+			return nil, p.errorAt(closing, "expected ')' to close '(' at position %d, found %s", tok.pos, closing.describe())
+		}
+		return node, nil
+	default:
+		return nil, p.errorAt(tok, "unexpected %s", tok.describe())
+	}
+}
+
+func (p *parser) parseFieldValue(field token) (Node, error) {
+	name := strings.ToLower(field.text)
+	switch name {
+	case FieldAuthor, FieldTag, FieldTitle, FieldPublished:
+	default:
+		return nil, p.errorAt(field, "unknown field '%s' (allowed: author, tag, title, published)", field.text)
+	}
+
+	value := p.next()
+	if value.kind != tokWord && value.kind != tokPhrase {
+		return nil, p.errorAt(value, "missing value for field '%s'", field.text)
+	}
+
+	if name == FieldPublished {
+		if value.kind == tokPhrase {
+			return nil, p.errorAt(value, "field 'published' expects a date, not a phrase")
+		}
+		return parseDateRange(name, value)
+	}
+	return &TermNode{Field: name, Value: value.text, Phrase: value.kind == tokPhrase, At: field.pos}, nil
+}
+
+func parseDateRange(field string, value token) (Node, error) {
+	op, date := "=", value.text
+	for _, candidate := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(date, candidate) {
+			op, date = candidate, strings.TrimPrefix(date, candidate)
+			break
+		}
+	}
+	if date == "" {
+		return nil, &SyntaxError{Position: value.pos, Message: "missing date after comparison operator"}
+	}
+	if _, err := time.Parse(time.DateOnly, date); err != nil {
+		if _, err := time.Parse(time.RFC3339, date); err != nil {
+			return nil, &SyntaxError{
+				Position: value.pos + len([]rune(value.text)) - len([]rune(date)),
+				Message:  fmt.Sprintf("invalid date '%s' (use YYYY-MM-DD or RFC3339)", date),
+			}
+		}
+	}
+	return &RangeNode{Field: field, Op: op, Value: date, At: value.pos}, nil
+}
@@ -0,0 +1,133 @@
+package querylang
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// dump menulis AST dalam bentuk ringkas agar mudah dibandingkan di tabel test.
+func dump(node Node) string {
+	switch n := node.(type) {
+	case nil:
+		return "<nil>"
+	case *TermNode:
+		value := n.Value
+		if n.Phrase {
+			value = fmt.Sprintf("%q", value)
+		}
+		if n.Field != "" {
+			value = n.Field + ":" + value
+		}
+		return fmt.Sprintf("%s@%d", value, n.At)
+	case *RangeNode:
+		return fmt.Sprintf("%s%s%s@%d", n.Field, n.Op, n.Value, n.At)
+	case *NotNode:
+		return fmt.Sprintf("NOT@%d(%s)", n.At, dump(n.Child))
+	case *AndNode:
+		return "AND(" + dumpAll(n.Children) + ")"
+	case *OrNode:
+		return "OR(" + dumpAll(n.Children) + ")"
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
+
+func dumpAll(nodes []Node) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = dump(node)
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "   ", "<nil>"},
+		{"single word", "pemilu", "pemilu@1"},
+		{"words", "pemilu jakarta", "AND(pemilu@1 jakarta@8)"},
+		{"explicit and", "pemilu AND jakarta", "AND(pemilu@1 jakarta@12)"},
+		{"phrase", `"pemilu serentak"`, `"pemilu serentak"@1`},
+		{"exclusion", "pemilu -hoaks", "AND(pemilu@1 NOT@8(hoaks@9))"},
+		{"excluded phrase", `-"kata kunci"`, `NOT@1("kata kunci"@2)`},
+		{"hyphenated word", "covid-19", "covid-19@1"},
+		{"lone minus is a word", "a - b", "AND(a@1 -@3 b@5)"},
+		{"or", "pemilu OR pilkada", "OR(pemilu@1 pilkada@11)"},
+		{"lowercase or is a word", "pemilu or pilkada", "AND(pemilu@1 or@8 pilkada@11)"},
+		{"and binds tighter than or", "a b OR c", "OR(AND(a@1 b@3) c@8)"},
+		{"grouping", "(a OR b) c", "AND(OR(a@2 b@7) c@10)"},
+		{"excluded group", "-(a OR b)", "NOT@1(OR(a@3 b@8))"},
+		{"author", "author:budi", "author:budi@1"},
+		{"tag", "tag:politik", "tag:politik@1"},
+		{"field is case insensitive", "Author:budi", "author:budi@1"},
+		{"title phrase", `title:"pemilu 2024"`, `title:"pemilu 2024"@1`},
+		{"published after", "published:>2024-01-01", "published>2024-01-01@11"},
+		{"published until", "published:<=2024-06-30", "published<=2024-06-30@11"},
+		{"published on", "published:2024-03-01", "published=2024-03-01@11"},
+		{"published rfc3339", "published:>=2024-03-01T08:00:00Z", "published>=2024-03-01T08:00:00Z@11"},
+		{"positions count runes", `"pâté" kota`, `AND("pâté"@1 kota@8)`},
+		{
+			name:  "combined",
+			input: `author:budi -tag:hoaks (pemilu OR pilkada) published:>2024-01-01`,
+			want:  "AND(author:budi@1 NOT@13(tag:hoaks@14) OR(pemilu@25 pilkada@35) published>2024-01-01@54)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.input, err)
+			}
+			if got := dump(node); got != tt.want {
+				t.Errorf("Parse(%q)\n got: %s\nwant: %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		position int
+		message  string
+	}{
+		{"unbalanced quote", `pemilu "serentak`, 8, "unterminated phrase"},
+		{"empty phrase", `pemilu "  "`, 8, "empty phrase"},
+		{"unknown field", "pemilu foo:bar", 8, "unknown field 'foo'"},
+		{"missing field value", "author:(budi)", 8, "missing value for field 'author'"},
+		{"missing value at end", "tag:", 5, "missing value for field 'tag'"},
+		{"published phrase", `published:"2024"`, 11, "expects a date"},
+		{"invalid date", "published:>2024-13-01", 12, "invalid date '2024-13-01'"},
+		{"missing date", "published:>=", 11, "missing date"},
+		{"unclosed paren", "(pemilu OR pilkada", 19, "expected ')' to close '(' at position 1"},
+		{"stray closing paren", "pemilu)", 7, "unexpected ')'"},
+		{"empty group", "()", 2, "expected a search term"},
+		{"dangling or", "pemilu OR", 10, "expected a search term after 'OR'"},
+		{"leading or", "OR pemilu", 1, "expected a search term before 'OR'"},
+		{"dangling and", "pemilu AND", 11, "expected a search term after 'AND'"},
+		{"too deep", strings.Repeat("(", maxDepth+1) + "a" + strings.Repeat(")", maxDepth+1), maxDepth + 1, "too many nested parentheses"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) = %s, %v; want *SyntaxError", tt.input, dump(node), err)
+			}
+			if syntaxErr.Position != tt.position {
+				t.Errorf("Parse(%q) error position = %d, want %d (%v)", tt.input, syntaxErr.Position, tt.position, err)
+			}
+			if !strings.Contains(syntaxErr.Message, tt.message) {
+				t.Errorf("Parse(%q) error message = %q, want it to contain %q", tt.input, syntaxErr.Message, tt.message)
+			}
+		})
+	}
+}
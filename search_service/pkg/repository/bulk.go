@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/elastic/go-elasticsearch/v9/esapi"
+)
+
+// Jenis aksi pada _bulk API.
+const (
+	BulkActionIndex  = "index"
+	BulkActionUpdate = "update"
+	BulkActionDelete = "delete"
+)
+
+// BulkOperation adalah satu aksi dalam permintaan _bulk. Body berisi dokumen untuk
+// aksi index, atau body update (mis. {"doc": {...}}) untuk aksi update. Jika Version
+// lebih dari 0, aksi memakai external versioning sehingga versi yang lebih lama ditolak.
+type BulkOperation struct {
+	Action     string
+	DocumentID string
+	Body       interface{}
+	Version    int64
+}
+
+// BulkItemResult adalah hasil satu aksi _bulk, dengan urutan yang sama seperti permintaannya.
+// Stale bernilai true jika aksi ditolak karena dokumen sudah memiliki versi yang sama
+// atau lebih baru; hal ini bukan error.
+type BulkItemResult struct {
+	DocumentID string
+	Status     int
+	Stale      bool
+	Err        error
+}
+
+type esBulkResponse struct {
+	Errors bool                            `json:"errors"`
+	Items  []map[string]esBulkResponseItem `json:"items"`
+}
+
+type esBulkResponseItem struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+// BulkWrite mengirim beberapa operasi sekaligus melalui _bulk API. Error yang
+// dikembalikan berarti seluruh permintaan gagal; kegagalan per dokumen dilaporkan
+// pada BulkItemResult.Err.
+func (r *ElasticSearchRepository) BulkWrite(ctx context.Context, indexName string, ops []BulkOperation) ([]BulkItemResult, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, op := range ops {
+		action := map[string]interface{}{"_index": indexName, "_id": op.DocumentID}
+		if op.Version > 0 {
+			action["version"] = op.Version
+			action["version_type"] = "external"
+		}
+		meta := map[string]interface{}{op.Action: action}
+		if err := encoder.Encode(meta); err != nil {
+			return nil, fmt.Errorf("failed to encode bulk action for ID %s: %w", op.DocumentID, err)
+		}
+		if op.Action == BulkActionDelete {
+			continue
+		}
+		if err := encoder.Encode(op.Body); err != nil {
+			return nil, fmt.Errorf("failed to encode bulk body for ID %s: %w", op.DocumentID, err)
+		}
+	}
+
+	req := esapi.BulkRequest{
+		Body:    &buf,
+		Refresh: r.Refresh,
+	}
+
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform bulk request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch returned an error during bulk request: %s", res.String())
+	}
+
+	var bulkRes esBulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&bulkRes); err != nil {
+		return nil, fmt.Errorf("failed to parse bulk response: %w", err)
+	}
+	if len(bulkRes.Items) != len(ops) {
+		return nil, fmt.Errorf("bulk response has %d items for %d operations", len(bulkRes.Items), len(ops))
+	}
+
+	results := make([]BulkItemResult, len(ops))
+	failed := 0
+	for i, item := range bulkRes.Items {
+		op := ops[i]
+		result := item[op.Action]
+		results[i] = BulkItemResult{DocumentID: op.DocumentID, Status: result.Status}
+
+		// Menghapus dokumen yang memang tidak ada dianggap berhasil, sama seperti DeleteDocument.
+		if op.Action == BulkActionDelete && result.Status == http.StatusNotFound {
+			continue
+		}
+		if op.Version > 0 && result.Status == http.StatusConflict {
+			results[i].Stale = true
+			continue
+		}
+		if result.Error != nil {
+			results[i].Err = fmt.Errorf("elasticsearch rejected %s for ID %s (status %d): %s: %s",
+				op.Action, op.DocumentID, result.Status, result.Error.Type, result.Error.Reason)
+			failed++
+		}
+	}
+
+	log.Printf("Bulk request to index '%s' completed: %d operations, %d failed.", indexName, len(ops), failed)
+	return results, nil
+}
+
+// VersionedDocument adalah _source dokumen beserta versinya, dipakai untuk menerapkan
+// partial update di atas dokumen yang sudah diindeks.
+type VersionedDocument struct {
+	Source  map[string]interface{}
+	Version int64
+	Deleted bool
+}
+
+type esMultiGetResponse struct {
+	Docs []struct {
+		ID      string                 `json:"_id"`
+		Found   bool                   `json:"found"`
+		Version int64                  `json:"_version"`
+		Source  map[string]interface{} `json:"_source"`
+		Error   *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"docs"`
+}
+
+// MultiGetDocuments mengambil beberapa dokumen sekaligus melalui _mget. Dokumen yang tidak
+// ada tidak dimasukkan ke hasil; tombstone dikembalikan dengan Deleted bernilai true.
+func (r *ElasticSearchRepository) MultiGetDocuments(ctx context.Context, indexName string, docIDs []string) (map[string]VersionedDocument, error) {
+	body, err := json.Marshal(map[string]interface{}{"ids": docIDs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mget request: %w", err)
+	}
+
+	req := esapi.MgetRequest{
+		Index:    indexName,
+		Body:     bytes.NewReader(body),
+		Realtime: esapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(ctx, r.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform mget request: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("elasticsearch returned an error during mget: %s", res.String())
+	}
+
+	var mgetRes esMultiGetResponse
+	if err := json.NewDecoder(res.Body).Decode(&mgetRes); err != nil {
+		return nil, fmt.Errorf("failed to parse mget response: %w", err)
+	}
+
+	docs := make(map[string]VersionedDocument, len(mgetRes.Docs))
+	for _, doc := range mgetRes.Docs {
+		if doc.Error != nil {
+			return nil, fmt.Errorf("elasticsearch rejected mget for ID %s: %s: %s", doc.ID, doc.Error.Type, doc.Error.Reason)
+		}
+		if !doc.Found {
+			continue
+		}
+		deleted, _ := doc.Source["deleted"].(bool)
+		docs[doc.ID] = VersionedDocument{Source: doc.Source, Version: doc.Version, Deleted: deleted}
+	}
+	return docs, nil
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/elastic/go-elasticsearch/v9"
 	"github.com/elastic/go-elasticsearch/v9/esapi"
@@ -11,11 +12,26 @@ import (
 	"net/http"
 	"search_service/pkg/config"
 	"search_service/pkg/model"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
 )
 
+// ErrPointInTimeNotFound dikembalikan jika point-in-time pada cursor sudah kedaluwarsa atau ditutup.
+var ErrPointInTimeNotFound = errors.New("point-in-time not found or expired")
+
 type ElasticSearchRepository struct {
-	Client *elasticsearch.Client
+	Client       *elasticsearch.Client
+	SearchFields []string // Field teks beserta boost-nya, misalnya "title^3"
+	// Refresh adalah kebijakan refresh untuk operasi tulis: "false", "wait_for" atau "true".
+	Refresh string
+	// SpellcheckEnabled bernilai false jika indeks dibuat tanpa sub-field trigram
+	// (misalnya indeks lama dengan dynamic mapping), sehingga phrase suggester tidak dipakai.
+	// Nilainya dapat berubah saat indeks dibuat ulang lewat endpoint admin.
+	SpellcheckEnabled atomic.Bool
+	// newsIndex adalah indeks berita yang disiapkan EnsureIndex.
+	newsIndex string
 }
 
 func NewElasticSearchRepository(cfg *config.AppConfig) (*ElasticSearchRepository, error) {
@@ -27,7 +43,25 @@ func NewElasticSearchRepository(cfg *config.AppConfig) (*ElasticSearchRepository
 		return nil, err
 	}
 
-	return &ElasticSearchRepository{Client: esClient}, nil
+	return &ElasticSearchRepository{
+		Client:       esClient,
+		SearchFields: buildSearchFields(cfg.SearchFieldBoosts),
+		Refresh:      cfg.ElasticSearchRefresh,
+	}, nil
+}
+
+// buildSearchFields mengubah map boost menjadi notasi "field^boost" yang dipahami multi_match.
+func buildSearchFields(boosts map[string]float64) []string {
+	fields := make([]string, 0, len(boosts))
+	for field, boost := range boosts {
+		if boost == 1 {
+			fields = append(fields, field)
+			continue
+		}
+		fields = append(fields, field+"^"+strconv.FormatFloat(boost, 'f', -1, 64))
+	}
+	sort.Strings(fields)
+	return fields
 }
 
 func (r *ElasticSearchRepository) Ping() error {
@@ -44,193 +78,267 @@ func (r *ElasticSearchRepository) Ping() error {
 	log.Println("Successfully connected to Elasticsearch!")
 	return nil
 }
-func (r *ElasticSearchRepository) IndexDocument(ctx context.Context, indexName string, doc model.DocumentNews) error {
-	docJSON, err := json.Marshal(doc)
+
+// EnsureIndex membuat indeks dengan NewsIndexMapping jika indeks tersebut belum ada.
+func (r *ElasticSearchRepository) EnsureIndex(ctx context.Context, indexName string) error {
+	r.newsIndex = indexName
+	existsRes, err := r.Client.Indices.Exists([]string{indexName}, r.Client.Indices.Exists.WithContext(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to marshal document: %w", err)
+		return fmt.Errorf("failed to check index existence: %w", err)
 	}
+	defer existsRes.Body.Close()
 
-	req := esapi.IndexRequest{
-		Index:      indexName,
-		DocumentID: doc.ID,
-		Body:       strings.NewReader(string(docJSON)),
-		Refresh:    "true",
+	if existsRes.StatusCode == http.StatusOK {
+		if err := r.detectSpellcheckSupport(ctx, indexName); err != nil {
+			return err
+		}
+		return r.checkSuggestSupport(ctx, indexName)
+	}
+	if existsRes.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("elasticsearch returned an error while checking index: %s", existsRes.String())
 	}
 
-	res, err := req.Do(ctx, r.Client)
+	createRes, err := r.Client.Indices.Create(
+		indexName,
+		r.Client.Indices.Create.WithBody(strings.NewReader(NewsIndexMapping)),
+		r.Client.Indices.Create.WithContext(ctx),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to perform index request: %w", err)
+		return fmt.Errorf("failed to create index: %w", err)
 	}
-	defer res.Body.Close()
+	defer createRes.Body.Close()
 
-	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned an error during indexing: %s", res.String())
+	if createRes.IsError() {
+		return fmt.Errorf("elasticsearch returned an error during index creation: %s", createRes.String())
 	}
 
-	log.Printf("Document ID %s indexed successfully to index '%s'.", doc.ID, indexName)
+	log.Printf("Index '%s' created with default news mapping.", indexName)
+	r.SpellcheckEnabled.Store(true)
 	return nil
 }
 
-func (r *ElasticSearchRepository) SearchDocuments(ctx context.Context, indexName string, query string, size, from int) ([]model.DocumentNews, int64, error) {
-	var buf bytes.Buffer
-	// Buat query dasar
-	searchBody := map[string]interface{}{}
-	if query == "" {
-		searchBody["query"] = map[string]interface{}{
-			"match_all": map[string]interface{}{},
-		}
-	} else {
-		searchBody["query"] = map[string]interface{}{
-			"match": map[string]interface{}{
-				"content": map[string]interface{}{
-					"query":     query,
-					"fuzziness": "AUTO",
-				},
-			},
-		}
+// RefreshIndexCapabilities memeriksa ulang fitur yang bergantung pada mapping setelah
+// indeks dibuat ulang, misalnya lewat endpoint admin. Indeks selain indeks berita diabaikan.
+func (r *ElasticSearchRepository) RefreshIndexCapabilities(ctx context.Context, indexName string) error {
+	if indexName != r.newsIndex {
+		return nil
 	}
+	return r.detectSpellcheckSupport(ctx, indexName)
+}
 
-	searchBody["size"] = size
-	searchBody["from"] = from
-
-	if err := json.NewEncoder(&buf).Encode(searchBody); err != nil {
-		return nil, 0, fmt.Errorf("failed to encode search query: %w", err)
+func (r *ElasticSearchRepository) detectSpellcheckSupport(ctx context.Context, indexName string) error {
+	enabled, err := r.hasField(ctx, indexName, "content.trigram")
+	if err != nil {
+		return err
 	}
+	r.SpellcheckEnabled.Store(enabled)
+	if !enabled {
+		log.Printf("Warning: Index '%s' has no trigram fields; recreate it with the default mapping to enable spelling suggestions.", indexName)
+	}
+	return nil
+}
 
-	req := esapi.SearchRequest{
-		Index: []string{indexName},
-		Body:  &buf,
+// checkSuggestSupport memperingatkan jika indeks lama belum punya sub-field title.suggest,
+// karena tanpa sub-field itu autocomplete judul tidak menghasilkan saran.
+func (r *ElasticSearchRepository) checkSuggestSupport(ctx context.Context, indexName string) error {
+	ok, err := r.hasField(ctx, indexName, "title.suggest")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		log.Printf("Warning: Index '%s' has no 'title.suggest' field; recreate it with the default mapping to enable title autocomplete.", indexName)
 	}
+	return nil
+}
 
-	res, err := req.Do(ctx, r.Client)
+// hasField melaporkan apakah field (termasuk sub-field) ada di mapping indeks.
+func (r *ElasticSearchRepository) hasField(ctx context.Context, indexName, field string) (bool, error) {
+	res, err := r.Client.Indices.GetFieldMapping(
+		[]string{field},
+		r.Client.Indices.GetFieldMapping.WithIndex(indexName),
+		r.Client.Indices.GetFieldMapping.WithContext(ctx),
+	)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to perform search request: %w", err)
+		return false, fmt.Errorf("failed to get field mapping: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return nil, 0, fmt.Errorf("elasticsearch returned an error during search: %s", res.String())
+		return false, fmt.Errorf("elasticsearch returned an error during get field mapping: %s", res.String())
 	}
 
-	var rMap map[string]interface{}
+	var rMap map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}
 	if err := json.NewDecoder(res.Body).Decode(&rMap); err != nil {
-		return nil, 0, fmt.Errorf("failed to parse search response: %w", err)
-	}
-
-	var news []model.DocumentNews
-	totalHits := int64(0)
-
-	hits, found := rMap["hits"].(map[string]interface{})
-	if found {
-		total := hits["total"].(map[string]interface{})["value"].(float64)
-		totalHits = int64(total)
-
-		for _, hit := range hits["hits"].([]interface{}) {
-			source := hit.(map[string]interface{})["_source"]
-			jsonBytes, _ := json.Marshal(source)
-			var doc model.DocumentNews
-			if err := json.Unmarshal(jsonBytes, &doc); err != nil {
-				log.Printf("Warning: Failed to unmarshal document from search result: %v", err)
-				continue
-			}
-			news = append(news, doc)
+		return false, fmt.Errorf("failed to parse field mapping response: %w", err)
+	}
+	return len(rMap[indexName].Mappings) > 0, nil
+}
+
+// SearchDocuments menjalankan pencarian. Jika searchReq.Cursor berisi point-in-time,
+// pencarian dilakukan terhadap PIT tersebut dengan search_after dan nilai from diabaikan.
+func (r *ElasticSearchRepository) SearchDocuments(ctx context.Context, indexName string, searchReq model.SearchRequest, size, from int) (*model.SearchResult, error) {
+	var buf bytes.Buffer
+	searchBody := r.buildSearchBody(searchReq)
+	searchBody["size"] = size
+
+	req := esapi.SearchRequest{
+		Body: &buf,
+	}
+	if cursor := searchReq.Cursor; cursor != nil && cursor.PitID != "" {
+		searchBody["pit"] = map[string]interface{}{
+			"id":         cursor.PitID,
+			"keep_alive": searchReq.CursorKeepAlive,
+		}
+		if len(cursor.SearchAfter) > 0 {
+			searchBody["search_after"] = cursor.SearchAfter
 		}
+	} else {
+		searchBody["from"] = from
+		req.Index = []string{indexName}
 	}
 
-	return news, totalHits, nil
-}
-func (r *ElasticSearchRepository) GetDocumentByID(ctx context.Context, indexName, docID string) (*model.DocumentNews, error) {
-	req := esapi.GetRequest{
-		Index:      indexName,
-		DocumentID: docID,
+	if err := json.NewEncoder(&buf).Encode(searchBody); err != nil {
+		return nil, fmt.Errorf("failed to encode search query: %w", err)
 	}
 
 	res, err := req.Do(ctx, r.Client)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform get document request: %w", err)
+		return nil, fmt.Errorf("failed to perform search request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		if res.StatusCode == http.StatusNotFound {
-			return nil, nil // Dokumen tidak ditemukan
+		if searchReq.Cursor != nil && res.StatusCode == http.StatusNotFound {
+			return nil, ErrPointInTimeNotFound
 		}
-		return nil, fmt.Errorf("elasticsearch returned an error during get document: %s", res.String())
+		return nil, fmt.Errorf("elasticsearch returned an error during search: %s", res.String())
 	}
 
-	var rMap map[string]interface{}
-	if err := json.NewDecoder(res.Body).Decode(&rMap); err != nil {
-		return nil, fmt.Errorf("failed to parse get document response: %w", err)
+	var esRes esSearchResponse
+	decoder := json.NewDecoder(res.Body)
+	decoder.UseNumber() // Nilai sort (mis. epoch millis) harus utuh untuk search_after.
+	if err := decoder.Decode(&esRes); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
 	}
 
-	found, _ := rMap["found"].(bool)
-	if !found {
-		return nil, nil
-	}
+	return esRes.toSearchResult(searchReq), nil
+}
 
-	source := rMap["_source"]
-	jsonBytes, err := json.Marshal(source)
+// OpenPointInTime membuka point-in-time agar hasil pencarian konsisten saat dipaginasi dengan cursor.
+func (r *ElasticSearchRepository) OpenPointInTime(ctx context.Context, indexName, keepAlive string) (string, error) {
+	res, err := r.Client.OpenPointInTime([]string{indexName}, keepAlive, r.Client.OpenPointInTime.WithContext(ctx))
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal source to JSON: %w", err)
+		return "", fmt.Errorf("failed to perform open point-in-time request: %w", err)
 	}
+	defer res.Body.Close()
 
-	var doc model.DocumentNews
-	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal document from get result: %w", err)
+	if res.IsError() {
+		return "", fmt.Errorf("elasticsearch returned an error during open point-in-time: %s", res.String())
 	}
 
-	return &doc, nil
+	var rMap struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&rMap); err != nil {
+		return "", fmt.Errorf("failed to parse open point-in-time response: %w", err)
+	}
+	return rMap.ID, nil
 }
-func (r *ElasticSearchRepository) UpdateDocument(ctx context.Context, indexName string, docID string, updates map[string]interface{}) error {
-	updateBody := map[string]interface{}{
-		"doc": updates,
+
+func (r *ElasticSearchRepository) ClosePointInTime(ctx context.Context, pitID string) error {
+	body, err := json.Marshal(map[string]string{"id": pitID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal close point-in-time body: %w", err)
 	}
-	updateJSON, err := json.Marshal(updateBody)
+
+	res, err := r.Client.ClosePointInTime(
+		r.Client.ClosePointInTime.WithBody(bytes.NewReader(body)),
+		r.Client.ClosePointInTime.WithContext(ctx),
+	)
 	if err != nil {
-		return fmt.Errorf("failed to marshal update document: %w", err)
+		return fmt.Errorf("failed to perform close point-in-time request: %w", err)
 	}
+	defer res.Body.Close()
 
-	req := esapi.UpdateRequest{
-		Index:      indexName,
-		DocumentID: docID,
-		Body:       strings.NewReader(string(updateJSON)),
-		Refresh:    "true",
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("elasticsearch returned an error during close point-in-time: %s", res.String())
+	}
+	return nil
+}
+
+func (r *ElasticSearchRepository) SuggestDocuments(ctx context.Context, indexName string, prefix string, size int) (*model.SuggestResult, error) {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(buildSuggestBody(prefix, size)); err != nil {
+		return nil, fmt.Errorf("failed to encode suggest query: %w", err)
+	}
+
+	req := esapi.SearchRequest{
+		Index: []string{indexName},
+		Body:  &buf,
 	}
 
 	res, err := req.Do(ctx, r.Client)
 	if err != nil {
-		return fmt.Errorf("failed to perform update request: %w", err)
+		return nil, fmt.Errorf("failed to perform suggest request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		return fmt.Errorf("elasticsearch returned an error during update: %s", res.String())
+		return nil, fmt.Errorf("elasticsearch returned an error during suggest: %s", res.String())
 	}
 
-	log.Printf("Document ID %s updated successfully in index '%s'.", docID, indexName)
-	return nil
+	var esRes esSearchResponse
+	if err := json.NewDecoder(res.Body).Decode(&esRes); err != nil {
+		return nil, fmt.Errorf("failed to parse suggest response: %w", err)
+	}
+
+	return esRes.toSuggestResult(), nil
 }
-func (r *ElasticSearchRepository) DeleteDocument(ctx context.Context, indexName, docID string) error {
-	req := esapi.DeleteRequest{
+
+func (r *ElasticSearchRepository) GetDocumentByID(ctx context.Context, indexName, docID string) (*model.DocumentNews, error) {
+	req := esapi.GetRequest{
 		Index:      indexName,
 		DocumentID: docID,
-		Refresh:    "true",
 	}
 
 	res, err := req.Do(ctx, r.Client)
 	if err != nil {
-		return fmt.Errorf("failed to perform delete request: %w", err)
+		return nil, fmt.Errorf("failed to perform get document request: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
 		if res.StatusCode == http.StatusNotFound {
-			log.Printf("Document ID %s not found in index '%s'. Nothing to delete.", docID, indexName)
-			return nil
+			return nil, nil // Dokumen tidak ditemukan
 		}
-		return fmt.Errorf("elasticsearch returned an error during delete: %s", res.String())
+		return nil, fmt.Errorf("elasticsearch returned an error during get document: %s", res.String())
 	}
 
-	log.Printf("Document ID %s deleted successfully from index '%s'.", docID, indexName)
-	return nil
+	var rMap map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&rMap); err != nil {
+		return nil, fmt.Errorf("failed to parse get document response: %w", err)
+	}
+
+	found, _ := rMap["found"].(bool)
+	if !found {
+		return nil, nil
+	}
+
+	source, _ := rMap["_source"].(map[string]interface{})
+	if deleted, _ := source["deleted"].(bool); deleted {
+		return nil, nil // Tombstone: artikel sudah dihapus
+	}
+	jsonBytes, err := json.Marshal(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal source to JSON: %w", err)
+	}
+
+	var doc model.DocumentNews
+	if err := json.Unmarshal(jsonBytes, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document from get result: %w", err)
+	}
+
+	return &doc, nil
 }
@@ -0,0 +1,85 @@
+package repository
+
+// NewsIndexMapping adalah settings dan mappings untuk indeks berita.
+// Sub-field "keyword" mengikuti bentuk dynamic mapping bawaan Elasticsearch sehingga
+// filter, sort dan facet tetap bekerja pada indeks lama. Sub-field "suggest"
+// (search_as_you_type) dipakai oleh endpoint autocomplete, dan sub-field "trigram"
+// (shingle) dipakai oleh phrase suggester untuk saran ejaan "did you mean".
+// Field "deleted" menandai tombstone artikel yang sudah dihapus, dan "last_event"
+// mencatat event terakhir (termasuk source dan subject CloudEvents) yang diterapkan.
+const NewsIndexMapping = `{
+  "settings": {
+    "analysis": {
+      "filter": {
+        "shingle_2_3": {
+          "type": "shingle",
+          "min_shingle_size": 2,
+          "max_shingle_size": 3
+        }
+      },
+      "analyzer": {
+        "trigram": {
+          "type": "custom",
+          "tokenizer": "standard",
+          "filter": ["lowercase", "shingle_2_3"]
+        }
+      }
+    }
+  },
+  "mappings": {
+    "properties": {
+      "id": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256 }
+        }
+      },
+      "title": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256 },
+          "suggest": { "type": "search_as_you_type" },
+          "trigram": { "type": "text", "analyzer": "trigram" }
+        }
+      },
+      "content": {
+        "type": "text",
+        "fields": {
+          "trigram": { "type": "text", "analyzer": "trigram" }
+        }
+      },
+      "author": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256 }
+        }
+      },
+      "tags": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256 }
+        }
+      },
+      "category": {
+        "type": "text",
+        "fields": {
+          "keyword": { "type": "keyword", "ignore_above": 256 }
+        }
+      },
+      "published_at": { "type": "date" },
+      "created_at": { "type": "date" },
+      "updated_at": { "type": "date" },
+      "deleted": { "type": "boolean" },
+      "deleted_at": { "type": "date" },
+      "last_event": {
+        "properties": {
+          "id": { "type": "keyword" },
+          "type": { "type": "keyword" },
+          "source": { "type": "keyword" },
+          "subject": { "type": "keyword" },
+          "occurred_at": { "type": "date" }
+        }
+      }
+    }
+  }
+}`
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"search_service/pkg/querylang"
+	"strings"
+	"time"
+)
+
+// queryFieldTargets memetakan field qualifier querylang ke field Elasticsearch.
+var queryFieldTargets = map[string]string{
+	querylang.FieldAuthor:    "author",
+	querylang.FieldTag:       "tags",
+	querylang.FieldTitle:     "title",
+	querylang.FieldPublished: "published_at",
+}
+
+// compileQuery mengubah AST querylang menjadi query DSL Elasticsearch.
+func (r *ElasticSearchRepository) compileQuery(node querylang.Node) map[string]interface{} {
+	switch n := node.(type) {
+	case *querylang.AndNode:
+		return r.compileAnd(n.Children)
+	case *querylang.OrNode:
+		should := make([]interface{}, 0, len(n.Children))
+		for _, child := range n.Children {
+			should = append(should, r.compileQuery(child))
+		}
+		return map[string]interface{}{
+			"bool": map[string]interface{}{"should": should, "minimum_should_match": 1},
+		}
+	case *querylang.NotNode:
+		return r.compileAnd([]querylang.Node{n})
+	case *querylang.TermNode:
+		return r.compileTerm(n)
+	case *querylang.RangeNode:
+		return compileRange(n)
+	default:
+		return map[string]interface{}{"match_none": map[string]interface{}{}}
+	}
+}
+
+// compileAnd menggabungkan kata bebas yang berurutan menjadi satu multi_match (seperti
+// pencarian teks biasa), sementara klausa lain menjadi must/must_not.
+func (r *ElasticSearchRepository) compileAnd(children []querylang.Node) map[string]interface{} {
+	var must, mustNot []interface{}
+	var freeWords []string
+
+	for _, child := range children {
+		switch c := child.(type) {
+		case *querylang.TermNode:
+			if c.Field == "" && !c.Phrase {
+				freeWords = append(freeWords, c.Value)
+				continue
+			}
+			must = append(must, r.compileQuery(c))
+		case *querylang.NotNode:
+			mustNot = append(mustNot, r.compileQuery(c.Child))
+		default:
+			must = append(must, r.compileQuery(c))
+		}
+	}
+	if len(freeWords) > 0 {
+		must = append([]interface{}{r.textQuery(strings.Join(freeWords, " "))}, must...)
+	}
+
+	if len(must) == 1 && len(mustNot) == 0 {
+		return must[0].(map[string]interface{})
+	}
+	if len(must) == 0 {
+		must = append(must, map[string]interface{}{"match_all": map[string]interface{}{}})
+	}
+	boolQuery := map[string]interface{}{"must": must}
+	if len(mustNot) > 0 {
+		boolQuery["must_not"] = mustNot
+	}
+	return map[string]interface{}{"bool": boolQuery}
+}
+
+// textQuery adalah pencarian teks bebas di semua field berbobot; setiap kata wajib cocok.
+func (r *ElasticSearchRepository) textQuery(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query":       text,
+			"fields":      r.SearchFields,
+			"type":        "best_fields",
+			"operator":    "and",
+			"tie_breaker": 0.3,
+			"fuzziness":   "AUTO",
+		},
+	}
+}
+
+func (r *ElasticSearchRepository) compileTerm(n *querylang.TermNode) map[string]interface{} {
+	if n.Field == "" {
+		if !n.Phrase {
+			return r.textQuery(n.Value)
+		}
+		return map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":  n.Value,
+				"fields": r.SearchFields,
+				"type":   "phrase",
+			},
+		}
+	}
+
+	field := queryFieldTargets[n.Field]
+	if n.Phrase {
+		return map[string]interface{}{
+			"match_phrase": map[string]interface{}{field: n.Value},
+		}
+	}
+	match := map[string]interface{}{
+		"query":    n.Value,
+		"operator": "and",
+	}
+	if n.Field == querylang.FieldTitle {
+		match["fuzziness"] = "AUTO"
+	}
+	return map[string]interface{}{
+		"match": map[string]interface{}{field: match},
+	}
+}
+
+// compileRange memakai pembulatan date math (||/d) untuk tanggal tanpa jam, sehingga
+// published:>2024-01-01 berarti setelah akhir hari tersebut.
+func compileRange(n *querylang.RangeNode) map[string]interface{} {
+	value := n.Value
+	if _, err := time.Parse(time.DateOnly, value); err == nil {
+		value += "||/d"
+	}
+
+	bounds := map[string]interface{}{}
+	switch n.Op {
+	case ">":
+		bounds["gt"] = value
+	case ">=":
+		bounds["gte"] = value
+	case "<":
+		bounds["lt"] = value
+	case "<=":
+		bounds["lte"] = value
+	default:
+		bounds["gte"] = value
+		bounds["lte"] = value
+	}
+	return map[string]interface{}{
+		"range": map[string]interface{}{queryFieldTargets[n.Field]: bounds},
+	}
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"encoding/json"
+	"search_service/pkg/querylang"
+	"testing"
+)
+
+func TestCompileQuery(t *testing.T) {
+	repo := &ElasticSearchRepository{SearchFields: []string{"content", "title^3"}}
+	text := func(query string) string {
+		return `{"multi_match":{"fields":["content","title^3"],"fuzziness":"AUTO","operator":"and",` +
+			`"query":"` + query + `","tie_breaker":0.3,"type":"best_fields"}}`
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "free words must all match",
+			query: "pemilu jakarta",
+			want:  text("pemilu jakarta"),
+		},
+		{
+			name:  "single free word",
+			query: "pemilu",
+			want:  text("pemilu"),
+		},
+		{
+			name:  "phrase",
+			query: `"pemilu serentak"`,
+			want:  `{"multi_match":{"fields":["content","title^3"],"query":"pemilu serentak","type":"phrase"}}`,
+		},
+		{
+			name:  "free words with exclusion",
+			query: "pemilu -hoaks",
+			want:  `{"bool":{"must":[` + text("pemilu") + `],"must_not":[` + text("hoaks") + `]}}`,
+		},
+		{
+			name:  "exclusion only",
+			query: "-hoaks",
+			want:  `{"bool":{"must":[{"match_all":{}}],"must_not":[` + text("hoaks") + `]}}`,
+		},
+		{
+			name:  "or",
+			query: "pemilu OR pilkada",
+			want:  `{"bool":{"minimum_should_match":1,"should":[` + text("pemilu") + `,` + text("pilkada") + `]}}`,
+		},
+		{
+			name:  "field qualifiers",
+			query: `author:budi title:"pemilu 2024"`,
+			want: `{"bool":{"must":[{"match":{"author":{"operator":"and","query":"budi"}}},` +
+				`{"match_phrase":{"title":"pemilu 2024"}}]}}`,
+		},
+		{
+			name:  "title word is fuzzy",
+			query: "title:pemilu",
+			want:  `{"match":{"title":{"fuzziness":"AUTO","operator":"and","query":"pemilu"}}}`,
+		},
+		{
+			name:  "tag targets tags field",
+			query: "tag:politik",
+			want:  `{"match":{"tags":{"operator":"and","query":"politik"}}}`,
+		},
+		{
+			name:  "date range is rounded to the day",
+			query: "published:>2024-01-01",
+			want:  `{"range":{"published_at":{"gt":"2024-01-01||/d"}}}`,
+		},
+		{
+			name:  "exact date covers the whole day",
+			query: "published:2024-03-01",
+			want:  `{"range":{"published_at":{"gte":"2024-03-01||/d","lte":"2024-03-01||/d"}}}`,
+		},
+		{
+			name:  "timestamp range is not rounded",
+			query: "published:<=2024-06-30T12:00:00Z",
+			want:  `{"range":{"published_at":{"lte":"2024-06-30T12:00:00Z"}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := querylang.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) returned error: %v", tt.query, err)
+			}
+			got, err := json.Marshal(repo.compileQuery(node))
+			if err != nil {
+				t.Fatalf("failed to marshal query: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("compileQuery(%q)\n got: %s\nwant: %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"search_service/pkg/model"
+	"time"
+)
+
+// idSortField dipakai sebagai tie-breaker agar urutan stabil antar halaman.
+const idSortField = "id.keyword"
+
+// deletedTombstoneQuery mencocokkan tombstone yang ditulis oleh event DELETED.
+var deletedTombstoneQuery = map[string]interface{}{
+	"term": map[string]interface{}{"deleted": true},
+}
+
+// facetFields memetakan nama facet publik ke field Elasticsearch yang diagregasi.
+var facetFields = map[string]string{
+	model.FacetTags:        "tags.keyword",
+	model.FacetAuthor:      "author.keyword",
+	model.FacetPublishedAt: "published_at",
+}
+
+// buildSearchBody menyusun body _search: query, sort, dan (jika diminta) agregasi facet.
+// Pada mode multi-select, filter milik facet dipindahkan ke post_filter supaya setiap
+// facet dapat menghitung ulang tanpa filternya sendiri.
+func (r *ElasticSearchRepository) buildSearchBody(req model.SearchRequest) map[string]interface{} {
+	groups := buildFilterGroups(req)
+	body := map[string]interface{}{
+		"sort": buildSort(req.Sort),
+	}
+	if req.SpellcheckText != "" && r.SpellcheckEnabled.Load() {
+		body["suggest"] = buildSpellcheckSuggest(req.SpellcheckText)
+	}
+	if req.Highlight.Enabled {
+		body["highlight"] = buildHighlight(req.Highlight)
+		if req.Highlight.Snippet {
+			body["_source"] = map[string]interface{}{"excludes": []string{"content"}}
+		}
+	}
+
+	if !req.MultiSelectFacets || len(req.Facets) == 0 {
+		body["query"] = r.buildSearchQuery(req, groups.all())
+		if len(req.Facets) > 0 {
+			body["aggs"] = buildFacetAggs(req, nil)
+		}
+		return body
+	}
+
+	body["query"] = r.buildSearchQuery(req, nil)
+	if postFilters := groups.all(); len(postFilters) > 0 {
+		body["post_filter"] = map[string]interface{}{
+			"bool": map[string]interface{}{"filter": postFilters},
+		}
+	}
+	body["aggs"] = buildFacetAggs(req, groups)
+	return body
+}
+
+func buildHighlight(opt model.HighlightOptions) map[string]interface{} {
+	contentField := map[string]interface{}{
+		"fragment_size":       opt.FragmentSize,
+		"number_of_fragments": opt.NumberOfFragments,
+	}
+	if opt.Snippet {
+		// Tetap kembalikan awal content sebagai snippet walaupun kecocokan ada di field lain.
+		contentField["no_match_size"] = opt.FragmentSize
+	}
+	return map[string]interface{}{
+		"pre_tags":            []string{opt.PreTag},
+		"post_tags":           []string{opt.PostTag},
+		"encoder":             "html",
+		"require_field_match": false,
+		"fields": map[string]interface{}{
+			"title":   map[string]interface{}{"number_of_fragments": 0},
+			"content": contentField,
+		},
+	}
+}
+
+// spellcheckSuggester adalah nama phrase suggester untuk saran "did you mean".
+const spellcheckSuggester = "did_you_mean"
+
+func buildSpellcheckSuggest(text string) map[string]interface{} {
+	return map[string]interface{}{
+		"text": text,
+		spellcheckSuggester: map[string]interface{}{
+			"phrase": map[string]interface{}{
+				"field":      "content.trigram",
+				"size":       1,
+				"gram_size":  3,
+				"max_errors": 2,
+				"direct_generator": []interface{}{
+					map[string]interface{}{
+						"field":        "content.trigram",
+						"suggest_mode": "always",
+					},
+					map[string]interface{}{
+						"field":        "title.trigram",
+						"suggest_mode": "always",
+					},
+				},
+				// Hanya sarankan koreksi yang benar-benar menghasilkan dokumen.
+				"collate": map[string]interface{}{
+					"query": map[string]interface{}{
+						"source": map[string]interface{}{
+							"multi_match": map[string]interface{}{
+								"query":    "{{suggestion}}",
+								"fields":   []string{"title", "content"},
+								"operator": "and",
+							},
+						},
+					},
+					"prune": false,
+				},
+			},
+		},
+	}
+}
+
+func buildSort(opt model.SortOption) []interface{} {
+	tieBreaker := map[string]interface{}{
+		idSortField: map[string]interface{}{"order": model.SortAsc, "unmapped_type": "keyword"},
+	}
+	if opt.Field == "" || opt.Field == model.SortRelevance {
+		return []interface{}{
+			map[string]interface{}{"_score": map[string]interface{}{"order": model.SortDesc}},
+			tieBreaker,
+		}
+	}
+	return []interface{}{
+		map[string]interface{}{
+			opt.Field: map[string]interface{}{
+				"order":         opt.Order,
+				"missing":       "_last",
+				"unmapped_type": "date",
+			},
+		},
+		tieBreaker,
+	}
+}
+
+// buildSearchQuery menggabungkan query teks (scoring) dengan filter (non-scoring) dalam satu bool query.
+func (r *ElasticSearchRepository) buildSearchQuery(req model.SearchRequest, filters []interface{}) map[string]interface{} {
+	var must map[string]interface{}
+	switch {
+	case req.RelatedTo != "":
+		must = map[string]interface{}{
+			"more_like_this": map[string]interface{}{
+				"fields":          []string{"title", "content", "tags"},
+				"like":            []interface{}{map[string]interface{}{"_id": req.RelatedTo}},
+				"min_term_freq":   1,
+				"min_doc_freq":    1,
+				"max_query_terms": 25,
+			},
+		}
+	case req.Expr != nil:
+		must = r.compileQuery(req.Expr)
+	default:
+		must = map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		}
+	}
+
+	// Tombstone artikel yang sudah dihapus tidak pernah ikut dalam hasil.
+	mustNot := []interface{}{deletedTombstoneQuery}
+	if req.RelatedTo != "" {
+		mustNot = append(mustNot, map[string]interface{}{
+			"ids": map[string]interface{}{"values": []string{req.RelatedTo}},
+		})
+	}
+	if len(req.ExcludeTags) > 0 {
+		mustNot = append(mustNot, map[string]interface{}{
+			"terms": map[string]interface{}{"tags.keyword": req.ExcludeTags},
+		})
+	}
+
+	boolQuery := map[string]interface{}{
+		"must":     must,
+		"must_not": mustNot,
+	}
+	if len(filters) > 0 {
+		boolQuery["filter"] = filters
+	}
+	return map[string]interface{}{"bool": boolQuery}
+}
+
+// filterGroups mengelompokkan klausa filter berdasarkan facet pemiliknya.
+type filterGroups map[string][]interface{}
+
+func (g filterGroups) all() []interface{} {
+	return g.except("")
+}
+
+func (g filterGroups) except(facet string) []interface{} {
+	var filters []interface{}
+	for _, name := range model.FacetNames {
+		if name != facet {
+			filters = append(filters, g[name]...)
+		}
+	}
+	return filters
+}
+
+func buildFilterGroups(req model.SearchRequest) filterGroups {
+	groups := filterGroups{}
+
+	if len(req.Tags) > 0 {
+		if req.TagMode == model.TagModeAll {
+			for _, tag := range req.Tags {
+				groups[model.FacetTags] = append(groups[model.FacetTags], map[string]interface{}{
+					"term": map[string]interface{}{"tags.keyword": tag},
+				})
+			}
+		} else {
+			groups[model.FacetTags] = append(groups[model.FacetTags], map[string]interface{}{
+				"terms": map[string]interface{}{"tags.keyword": req.Tags},
+			})
+		}
+	}
+
+	if req.Author != "" {
+		groups[model.FacetAuthor] = append(groups[model.FacetAuthor], map[string]interface{}{
+			"term": map[string]interface{}{"author.keyword": req.Author},
+		})
+	}
+
+	if req.PublishedFrom != nil || req.PublishedTo != nil {
+		dateRange := map[string]interface{}{}
+		if req.PublishedFrom != nil {
+			dateRange["gte"] = req.PublishedFrom.Format(time.RFC3339Nano)
+		}
+		if req.PublishedTo != nil {
+			dateRange["lte"] = req.PublishedTo.Format(time.RFC3339Nano)
+		}
+		groups[model.FacetPublishedAt] = append(groups[model.FacetPublishedAt], map[string]interface{}{
+			"range": map[string]interface{}{"published_at": dateRange},
+		})
+	}
+
+	return groups
+}
+
+// buildFacetAggs membungkus setiap agregasi facet dalam agregasi "filter".
+// Jika groups tidak nil (mode multi-select), filter tersebut berisi semua filter facet
+// lain sehingga hitungan facet mengabaikan filternya sendiri.
+func buildFacetAggs(req model.SearchRequest, groups filterGroups) map[string]interface{} {
+	aggs := make(map[string]interface{}, len(req.Facets))
+	for _, facet := range req.Facets {
+		var inner map[string]interface{}
+		if facet.Name == model.FacetPublishedAt {
+			inner = map[string]interface{}{
+				"date_histogram": map[string]interface{}{
+					"field":             facetFields[facet.Name],
+					"calendar_interval": facet.Interval,
+					"format":            "yyyy-MM-dd",
+					"min_doc_count":     1,
+				},
+			}
+		} else {
+			inner = map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": facetFields[facet.Name],
+					"size":  facet.Size,
+				},
+			}
+		}
+
+		filter := map[string]interface{}{"match_all": map[string]interface{}{}}
+		if others := groups.except(facet.Name); len(others) > 0 {
+			filter = map[string]interface{}{
+				"bool": map[string]interface{}{"filter": others},
+			}
+		}
+
+		aggs[facet.Name] = map[string]interface{}{
+			"filter": filter,
+			"aggs": map[string]interface{}{
+				facetBucketsAgg: inner,
+			},
+		}
+	}
+	return aggs
+}
@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"search_service/pkg/model"
+	"strings"
+)
+
+// facetBucketsAgg adalah nama sub-agregasi yang berisi bucket facet di dalam agregasi filter.
+const facetBucketsAgg = "buckets"
+
+// esSearchResponse adalah bagian dari respons _search Elasticsearch yang kita gunakan.
+type esSearchResponse struct {
+	Hits struct {
+		Total struct {
+			Value int64 `json:"value"`
+		} `json:"total"`
+		Hits []esHit `json:"hits"`
+	} `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations"`
+	Suggest      map[string][]esSuggestion  `json:"suggest"`
+	PitID        string                     `json:"pit_id"`
+}
+
+type esSuggestion struct {
+	Text    string `json:"text"`
+	Options []struct {
+		Text  string  `json:"text"`
+		Score float64 `json:"score"`
+	} `json:"options"`
+}
+
+type esHit struct {
+	ID        string              `json:"_id"`
+	Score     *float64            `json:"_score"`
+	Source    json.RawMessage     `json:"_source"`
+	Highlight map[string][]string `json:"highlight"`
+	Sort      []interface{}       `json:"sort"`
+}
+
+type esFacetAgg struct {
+	Buckets esTermsAgg `json:"buckets"`
+}
+
+type esTermsAgg struct {
+	Buckets []esBucket `json:"buckets"`
+}
+
+type esBucket struct {
+	Key         interface{} `json:"key"`
+	KeyAsString string      `json:"key_as_string"`
+	DocCount    int64       `json:"doc_count"`
+}
+
+func (r esSearchResponse) toSearchResult(req model.SearchRequest) *model.SearchResult {
+	result := &model.SearchResult{
+		TotalHits: r.Hits.Total.Value,
+		Articles:  make([]model.SearchHit, 0, len(r.Hits.Hits)),
+		PitID:     r.PitID,
+	}
+	if n := len(r.Hits.Hits); n > 0 {
+		result.LastSort = r.Hits.Hits[n-1].Sort
+	}
+
+	for _, hit := range r.Hits.Hits {
+		var doc model.DocumentNews
+		if err := json.Unmarshal(hit.Source, &doc); err != nil {
+			log.Printf("Warning: Failed to unmarshal document %s from search result: %v", hit.ID, err)
+			continue
+		}
+		if req.Highlight.Snippet {
+			doc.Content = strings.Join(hit.Highlight["content"], " ... ")
+		}
+		result.Articles = append(result.Articles, model.SearchHit{
+			DocumentNews: doc,
+			Highlights:   hit.Highlight,
+		})
+	}
+
+	if suggestions := r.Suggest[spellcheckSuggester]; len(suggestions) > 0 && len(suggestions[0].Options) > 0 {
+		result.DidYouMean = suggestions[0].Options[0].Text
+	}
+
+	if len(req.Facets) > 0 {
+		result.Facets = make(map[string][]model.FacetBucket, len(req.Facets))
+		for _, facet := range req.Facets {
+			var agg esFacetAgg
+			if raw, ok := r.Aggregations[facet.Name]; ok {
+				if err := json.Unmarshal(raw, &agg); err != nil {
+					log.Printf("Warning: Failed to parse facet '%s' from search result: %v", facet.Name, err)
+				}
+			}
+			buckets := make([]model.FacetBucket, 0)
+			for _, b := range agg.Buckets.Buckets {
+				key := b.KeyAsString
+				if key == "" {
+					key = fmt.Sprint(b.Key)
+				}
+				buckets = append(buckets, model.FacetBucket{Key: key, Count: b.DocCount})
+			}
+			result.Facets[facet.Name] = buckets
+		}
+	}
+
+	return result
+}
+
+func (r esSearchResponse) toSuggestResult() *model.SuggestResult {
+	result := &model.SuggestResult{
+		Titles:  make([]model.TitleSuggestion, 0, len(r.Hits.Hits)),
+		Tags:    r.termsAggKeys(suggestTagsAgg),
+		Authors: r.termsAggKeys(suggestAuthorsAgg),
+	}
+	for _, hit := range r.Hits.Hits {
+		var suggestion model.TitleSuggestion
+		if err := json.Unmarshal(hit.Source, &suggestion); err != nil {
+			log.Printf("Warning: Failed to unmarshal title suggestion %s: %v", hit.ID, err)
+			continue
+		}
+		result.Titles = append(result.Titles, suggestion)
+	}
+	return result
+}
+
+func (r esSearchResponse) termsAggKeys(name string) []string {
+	keys := make([]string, 0)
+	raw, ok := r.Aggregations[name]
+	if !ok {
+		return keys
+	}
+	var agg esTermsAgg
+	if err := json.Unmarshal(raw, &agg); err != nil {
+		log.Printf("Warning: Failed to parse aggregation '%s': %v", name, err)
+		return keys
+	}
+	for _, b := range agg.Buckets {
+		keys = append(keys, fmt.Sprint(b.Key))
+	}
+	return keys
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	suggestTagsAgg    = "tags"
+	suggestAuthorsAgg = "authors"
+)
+
+var titleSuggestFields = []string{"title.suggest", "title.suggest._2gram", "title.suggest._3gram"}
+
+// buildSuggestBody menyusun query autocomplete. Query utama mencari dokumen yang
+// cocok pada title, tags atau author; post_filter membatasi hits ke judul saja,
+// sedangkan agregasi mengambil nilai tag/author yang diawali teks yang diketik.
+func buildSuggestBody(prefix string, size int) map[string]interface{} {
+	titleQuery := map[string]interface{}{
+		"multi_match": map[string]interface{}{
+			"query":  prefix,
+			"type":   "bool_prefix",
+			"fields": titleSuggestFields,
+		},
+	}
+	include := prefixIncludePattern(prefix)
+
+	return map[string]interface{}{
+		"size":    size,
+		"_source": []string{"id", "title"},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					titleQuery,
+					map[string]interface{}{
+						"multi_match": map[string]interface{}{
+							"query":  prefix,
+							"type":   "bool_prefix",
+							"fields": []string{"tags", "author"},
+						},
+					},
+				},
+				"minimum_should_match": 1,
+				"must_not":             []interface{}{deletedTombstoneQuery},
+			},
+		},
+		"post_filter": titleQuery,
+		"aggs": map[string]interface{}{
+			suggestTagsAgg: map[string]interface{}{
+				"terms": map[string]interface{}{"field": "tags.keyword", "size": size, "include": include},
+			},
+			suggestAuthorsAgg: map[string]interface{}{
+				"terms": map[string]interface{}{"field": "author.keyword", "size": size, "include": include},
+			},
+		},
+	}
+}
+
+// prefixIncludePattern membuat regex Lucene yang mencocokkan nilai dengan kata yang
+// diawali prefix, tanpa membedakan huruf besar/kecil (mis. "bud" -> "(.* )?[bB][uU][dD].*").
+func prefixIncludePattern(prefix string) string {
+	var b strings.Builder
+	b.WriteString("(.* )?")
+	for _, r := range strings.TrimSpace(prefix) {
+		lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+		switch {
+		case lower != upper:
+			b.WriteString("[" + string(lower) + string(upper) + "]")
+		case strings.ContainsRune(`.?+*|{}[]()"\#@&<>~`, r):
+			b.WriteString(`\` + string(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteString(".*")
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package service
+
+import "errors"
+
+// ErrTargetDocumentMissing dikembalikan jika event ditujukan ke artikel yang belum
+// diindeks atau sudah dihapus dan kebijakannya bukan upsert.
+var ErrTargetDocumentMissing = errors.New("target document does not exist")
+
+// errStaleEvent menandai event yang versinya tidak lebih baru dari dokumen saat ini.
+var errStaleEvent = errors.New("stale event")
+
+// PermanentError menandai kegagalan yang tidak akan berhasil walaupun dicoba ulang,
+// sehingga consumer langsung memindahkan pesannya ke dead-letter queue.
+type PermanentError struct {
+	Reason string
+	Err    error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Reason + ": " + e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+func permanent(reason string, err error) error {
+	return &PermanentError{Reason: reason, Err: err}
+}
+
+// IsPermanent melaporkan apakah err (atau error yang dibungkusnya) adalah PermanentError.
+func IsPermanent(err error) bool {
+	var permanentErr *PermanentError
+	return errors.As(err, &permanentErr)
+}
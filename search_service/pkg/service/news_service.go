@@ -2,49 +2,210 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log" // Ditambahkan
+	"net/http"
+	"search_service/pkg/config"
 	"search_service/pkg/model"
+	"search_service/pkg/querylang"
 	"search_service/pkg/repository"
+	"strings"
 	"time"
 )
 
 type NewsService struct {
 	ESRepo    *repository.ElasticSearchRepository
 	IndexName string // Nama indeks yang akan digunakan
+	// SpellcheckMaxHits adalah batas jumlah hasil yang masih memunculkan saran "did you mean".
+	SpellcheckMaxHits int64
+	PitKeepAlive      string
+	// MissingDocumentPolicy menentukan perlakuan event yang targetnya belum ada, per jenis event.
+	MissingDocumentPolicy map[string]string
 }
 
-func NewNewsService(esRepo *repository.ElasticSearchRepository) *NewsService {
+func NewNewsService(esRepo *repository.ElasticSearchRepository, cfg *config.AppConfig) *NewsService {
 	return &NewsService{
-		ESRepo:    esRepo,
-		IndexName: "news_articles",
+		ESRepo:            esRepo,
+		IndexName:         "news_articles",
+		SpellcheckMaxHits: cfg.SpellcheckMaxHits,
+		PitKeepAlive:      cfg.PitKeepAlive,
+
+		MissingDocumentPolicy: cfg.MissingDocumentPolicy,
 	}
 }
 
-func (s *NewsService) IndexNews(ctx context.Context, doc model.DocumentNews) error {
-	// Di sini bisa ada validasi data atau transformasi sebelum diindeks
-	log.Printf("Service: Indexing news document with ID: %s", doc.ID)
-	now := time.Now()
-	doc.CreatedAt = now
-	return s.ESRepo.IndexDocument(ctx, s.IndexName, doc)
+// normalizeSearchRequest mengisi nilai default pagination, tag mode dan sort.
+func normalizeSearchRequest(req model.SearchRequest) model.SearchRequest {
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 {
+		req.Limit = 10
+	}
+	if req.TagMode == "" {
+		req.TagMode = model.TagModeAny
+	}
+	if req.Sort.Field == "" {
+		req.Sort = model.SortOption{Field: model.SortRelevance, Order: model.SortDesc}
+	}
+	return req
 }
 
-func (s *NewsService) SearchNewsArticles(ctx context.Context, query string, page, limit int) ([]model.DocumentNews, int64, error) {
-	if page < 1 {
-		page = 1
+// parseQuery mem-parsing sintaks query pengguna. Kesalahan sintaks dikembalikan sebagai
+// *querylang.SyntaxError (ter-wrap) agar handler dapat membalas 400 beserta posisinya.
+func parseQuery(req *model.SearchRequest) error {
+	expr, err := querylang.Parse(req.Query)
+	if err != nil {
+		return fmt.Errorf("invalid search query: %w", err)
 	}
-	if limit < 1 {
-		limit = 10
+	req.Expr = expr
+	req.SpellcheckText = ""
+	if expr != nil {
+		req.SpellcheckText = querylang.FreeText(expr)
+	}
+	return nil
+}
+
+func (s *NewsService) SearchNewsArticles(ctx context.Context, req model.SearchRequest) (*model.SearchResult, error) {
+	req = normalizeSearchRequest(req)
+	if err := parseQuery(&req); err != nil {
+		return nil, err
+	}
+	if req.UseCursor {
+		return s.searchWithCursor(ctx, req)
+	}
+	from := (req.Page - 1) * req.Limit
+
+	log.Printf("Service: Searching news for query '%s', page %d, limit %d", req.Query, req.Page, req.Limit)
+	result, err := s.ESRepo.SearchDocuments(ctx, s.IndexName, req, req.Limit, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search news articles: %w", err)
+	}
+	result.ExecutedQuery = req.Query
+
+	if result.TotalHits > s.SpellcheckMaxHits || strings.EqualFold(result.DidYouMean, req.SpellcheckText) {
+		result.DidYouMean = ""
+		return result, nil
+	}
+	// Koreksi otomatis hanya untuk query teks biasa agar sintaks query pengguna tidak berubah.
+	if result.DidYouMean == "" || !req.AutoCorrect || !querylang.IsPlainText(req.Expr) {
+		return result, nil
+	}
+
+	corrected := req
+	corrected.Query = result.DidYouMean
+	if err := parseQuery(&corrected); err != nil {
+		return result, nil
+	}
+	log.Printf("Service: Re-running search with corrected query '%s' (original '%s')", corrected.Query, req.Query)
+	correctedResult, err := s.ESRepo.SearchDocuments(ctx, s.IndexName, corrected, req.Limit, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search news articles with corrected query: %w", err)
+	}
+	if correctedResult.TotalHits <= result.TotalHits {
+		return result, nil
+	}
+	correctedResult.DidYouMean = result.DidYouMean
+	correctedResult.ExecutedQuery = corrected.Query
+	return correctedResult, nil
+}
+
+// searchWithCursor menjalankan pencarian dengan point-in-time dan search_after sehingga
+// seluruh hasil dapat ditelusuri melewati batas 10.000 hasil tanpa bergeser antar halaman.
+func (s *NewsService) searchWithCursor(ctx context.Context, req model.SearchRequest) (*model.SearchResult, error) {
+	req.AutoCorrect = false
+	req.CursorKeepAlive = s.PitKeepAlive
+	queryHash := model.CursorQueryHash(req)
+	opened := req.Cursor == nil
+	if opened {
+		pitID, err := s.ESRepo.OpenPointInTime(ctx, s.IndexName, s.PitKeepAlive)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open search cursor: %w", err)
+		}
+		req.Cursor = &model.SearchCursor{PitID: pitID}
+	} else if req.Cursor.QueryHash != queryHash {
+		return nil, model.ErrCursorMismatch
+	}
+
+	log.Printf("Service: Searching news for query '%s' with cursor, limit %d", req.Query, req.Limit)
+	result, err := s.ESRepo.SearchDocuments(ctx, s.IndexName, req, req.Limit, 0)
+	if err != nil {
+		// Point-in-time yang baru dibuka belum diketahui client, jadi harus ditutup di sini.
+		if opened {
+			if closeErr := s.ESRepo.ClosePointInTime(ctx, req.Cursor.PitID); closeErr != nil {
+				log.Printf("Warning: Failed to close point-in-time: %v", closeErr)
+			}
+		}
+		return nil, fmt.Errorf("failed to search news articles: %w", err)
+	}
+	result.ExecutedQuery = req.Query
+	if result.TotalHits > s.SpellcheckMaxHits {
+		result.DidYouMean = ""
+	}
+
+	pitID := result.PitID
+	if pitID == "" {
+		pitID = req.Cursor.PitID
+	}
+	if len(result.Articles) < req.Limit || len(result.LastSort) == 0 {
+		if err := s.ESRepo.ClosePointInTime(ctx, pitID); err != nil {
+			log.Printf("Warning: Failed to close point-in-time: %v", err)
+		}
+		return result, nil
+	}
+
+	next, err := model.EncodeCursor(model.SearchCursor{PitID: pitID, SearchAfter: result.LastSort, QueryHash: queryHash})
+	if err != nil {
+		return nil, err
+	}
+	result.NextCursor = next
+	return result, nil
+}
+
+// RelatedNewsArticles mencari artikel yang mirip dengan artikel sumber (more-like-this).
+// Mengembalikan nil, nil jika artikel sumber tidak ditemukan.
+func (s *NewsService) RelatedNewsArticles(ctx context.Context, id string, req model.SearchRequest) (*model.SearchResult, error) {
+	source, err := s.GetNewsArticleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, nil
+	}
+
+	req = normalizeSearchRequest(req)
+	req.Query = ""
+	req.AutoCorrect = false
+	req.UseCursor, req.Cursor = false, nil
+	req.Expr, req.SpellcheckText = nil, ""
+	req.RelatedTo = id
+	from := (req.Page - 1) * req.Limit
+
+	log.Printf("Service: Searching news related to ID '%s', page %d, limit %d", id, req.Page, req.Limit)
+	result, err := s.ESRepo.SearchDocuments(ctx, s.IndexName, req, req.Limit, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search related news articles: %w", err)
 	}
+	return result, nil
+}
 
-	from := (page - 1) * limit
+func (s *NewsService) SuggestNews(ctx context.Context, prefix string, limit int) (*model.SuggestResult, error) {
+	if limit < 1 {
+		limit = 5
+	}
 
-	log.Printf("Service: Searching news for query '%s', page %d, limit %d", query, page, limit)
-	news, total, err := s.ESRepo.SearchDocuments(ctx, s.IndexName, query, limit, from)
+	log.Printf("Service: Suggesting news for prefix '%s', limit %d", prefix, limit)
+	result, err := s.ESRepo.SuggestDocuments(ctx, s.IndexName, prefix, limit)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to search news articles: %w", err)
+		return nil, fmt.Errorf("failed to suggest news articles: %w", err)
 	}
-	return news, total, nil
+	return result, nil
+}
+
+// EnsureIndex memastikan indeks berita ada dengan mapping yang dibutuhkan (termasuk autocomplete).
+func (s *NewsService) EnsureIndex(ctx context.Context) error {
+	return s.ESRepo.EnsureIndex(ctx, s.IndexName)
 }
 
 func (s *NewsService) GetNewsArticleByID(ctx context.Context, id string) (*model.DocumentNews, error) {
@@ -56,17 +217,147 @@ func (s *NewsService) GetNewsArticleByID(ctx context.Context, id string) (*model
 	return doc, nil
 }
 
-// UpdateNews (akan dipanggil oleh consumer RabbitMQ)
-func (s *NewsService) UpdateNews(ctx context.Context, docID string, updates map[string]interface{}) error {
+// ApplyEvents menerapkan sekumpulan event sekaligus melalui _bulk API. Hasilnya berupa
+// satu error per event (nil jika berhasil) dengan urutan yang sama seperti events,
+// sehingga consumer dapat meng-ack atau me-retry setiap pesan secara terpisah.
+func (s *NewsService) ApplyEvents(ctx context.Context, events []model.NewsEvent) []error {
+	errs := make([]error, len(events))
+	ops := make([]repository.BulkOperation, 0, len(events))
+	opIndex := make([]int, 0, len(events))
+
+	current, err := s.loadTargets(ctx, events)
+	if err != nil {
+		for i, event := range events {
+			if s.needsTarget(event) {
+				errs[i] = err
+			}
+		}
+	}
+
 	now := time.Now()
-	updates["updated_at"] = now
+	for i, event := range events {
+		if errs[i] != nil {
+			continue
+		}
 
-	log.Printf("Service: Updating news document with ID: %s", docID)
-	return s.ESRepo.UpdateDocument(ctx, s.IndexName, docID, updates)
+		var op repository.BulkOperation
+		var err error
+		skip := false
+		if s.needsTarget(event) {
+			skip, err = s.checkTarget(event, current)
+		}
+		if skip {
+			log.Printf("Service: Skipping %s event for missing document ID %s.", event.Type, event.Payload.ID)
+			continue
+		}
+		if err == nil {
+			if event.IsPartialUpdate() {
+				op, err = buildPartialUpdateOperation(event, current, now)
+			} else {
+				op, err = buildBulkOperation(event, now)
+			}
+		}
+		if errors.Is(err, errStaleEvent) {
+			log.Printf("Service: Ignoring stale %s event for ID %s (version %d is not newer than the indexed document).",
+				event.Type, event.Payload.ID, event.Version)
+			continue
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		if op.Body, err = withEventMetadata(op.Body, event); err != nil {
+			errs[i] = err
+			continue
+		}
+		if current != nil {
+			current.apply(op)
+		}
+		ops = append(ops, op)
+		opIndex = append(opIndex, i)
+	}
+	if len(ops) == 0 {
+		return errs
+	}
+
+	log.Printf("Service: Applying %d news events in bulk", len(ops))
+	results, err := s.ESRepo.BulkWrite(ctx, s.IndexName, ops)
+	if err != nil {
+		for _, i := range opIndex {
+			errs[i] = fmt.Errorf("failed to apply news events: %w", err)
+		}
+		return errs
+	}
+	for j, result := range results {
+		if result.Stale {
+			event := events[opIndex[j]]
+			log.Printf("Service: Ignoring stale %s event for ID %s (version %d is not newer than the indexed document).",
+				event.Type, event.Payload.ID, ops[j].Version)
+		}
+		err := result.Err
+		if err != nil && result.Status == http.StatusBadRequest {
+			// Dokumen ditolak mapping (mis. tipe field salah); mencoba ulang tidak akan membantu.
+			err = permanent("rejected by elasticsearch", err)
+		}
+		errs[opIndex[j]] = err
+	}
+	return errs
 }
 
-// DeleteNews (akan dipanggil oleh consumer RabbitMQ)
-func (s *NewsService) DeleteNews(ctx context.Context, docID string) error {
-	log.Printf("Service: Deleting news document with ID: %s", docID)
-	return s.ESRepo.DeleteDocument(ctx, s.IndexName, docID)
+// buildBulkOperation mengubah event menjadi operasi index dengan external versioning.
+// UPDATED tanpa field mask membawa dokumen lengkap sehingga ditulis ulang utuh
+// (lihat buildPartialUpdateOperation untuk UPDATED dengan mask), dan DELETED menulis
+// tombstone (bukan menghapus dokumen) agar CREATED yang terlambat tidak menghidupkan
+// kembali artikel yang sudah dihapus.
+func buildBulkOperation(event model.NewsEvent, now time.Time) (repository.BulkOperation, error) {
+	doc := event.Payload
+	op := repository.BulkOperation{
+		Action:     repository.BulkActionIndex,
+		DocumentID: doc.ID,
+		Version:    event.Version,
+	}
+
+	switch event.Type {
+	case model.EventCreated:
+		if doc.CreatedAt.IsZero() {
+			doc.CreatedAt = now
+		}
+		op.Body = doc
+	case model.EventUpdated:
+		if doc.UpdatedAt == nil {
+			doc.UpdatedAt = &now
+		}
+		op.Body = doc
+	case model.EventDeleted:
+		op.Body = map[string]interface{}{
+			"id":         doc.ID,
+			"deleted":    true,
+			"deleted_at": now,
+		}
+	default:
+		return repository.BulkOperation{}, permanent("invalid event", fmt.Errorf("unknown event type '%s'", event.Type))
+	}
+	return op, nil
+}
+
+// withEventMetadata menambahkan field "last_event" (id, source, subject dan jenis event
+// terakhir yang diterapkan) ke body dokumen agar asal perubahan dapat ditelusuri.
+func withEventMetadata(body interface{}, event model.NewsEvent) (map[string]interface{}, error) {
+	source, err := toSourceMap(body)
+	if err != nil {
+		return nil, err
+	}
+	lastEvent := map[string]interface{}{
+		"id":          event.EventID,
+		"type":        event.Type,
+		"occurred_at": event.OccurredAt,
+	}
+	if event.Source != "" {
+		lastEvent["source"] = event.Source
+	}
+	if event.Subject != "" {
+		lastEvent["subject"] = event.Subject
+	}
+	source["last_event"] = lastEvent
+	return source, nil
 }
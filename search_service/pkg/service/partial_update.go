@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"search_service/pkg/config"
+	"search_service/pkg/model"
+	"search_service/pkg/repository"
+	"slices"
+	"time"
+)
+
+// documentSnapshots menyimpan versi terbaru dokumen yang menjadi target event dalam
+// satu batch, termasuk hasil operasi sebelumnya di batch yang sama, sehingga
+// beberapa partial update untuk artikel yang sama diterapkan secara berurutan.
+type documentSnapshots map[string]repository.VersionedDocument
+
+// loadTargets mengambil dokumen saat ini dengan satu permintaan _mget untuk semua
+// event yang perlu tahu apakah targetnya ada. Mengembalikan nil jika tidak ada.
+func (s *NewsService) loadTargets(ctx context.Context, events []model.NewsEvent) (documentSnapshots, error) {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, event := range events {
+		if s.needsTarget(event) && !seen[event.Payload.ID] {
+			seen[event.Payload.ID] = true
+			ids = append(ids, event.Payload.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	docs, err := s.ESRepo.MultiGetDocuments(ctx, s.IndexName, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load target documents: %w", err)
+	}
+	return documentSnapshots(docs), nil
+}
+
+// missingDocumentPolicy mengembalikan kebijakan event saat dokumen targetnya tidak ada.
+// CREATED selalu upsert.
+func (s *NewsService) missingDocumentPolicy(event model.NewsEvent) string {
+	key := event.Type
+	if event.IsPartialUpdate() {
+		key = config.PartialUpdateEventKey
+	}
+	if policy, ok := s.MissingDocumentPolicy[key]; ok {
+		return policy
+	}
+	return config.MissingDocUpsert
+}
+
+// needsTarget melaporkan apakah event memerlukan dokumen saat ini: partial update
+// selalu, event lain hanya jika kebijakan dokumen hilangnya bukan upsert.
+func (s *NewsService) needsTarget(event model.NewsEvent) bool {
+	return event.IsPartialUpdate() || s.missingDocumentPolicy(event) != config.MissingDocUpsert
+}
+
+// checkTarget memeriksa event terhadap dokumen saat ini. Mengembalikan errStaleEvent
+// untuk event usang, nil jika event boleh ditulis, atau error sesuai kebijakan dokumen
+// hilang. skip bernilai true jika event harus di-ack tanpa menulis apa pun.
+func (s *NewsService) checkTarget(event model.NewsEvent, current documentSnapshots) (skip bool, err error) {
+	docID := event.Payload.ID
+	target, ok := current[docID]
+	if ok && event.Version > 0 && event.Version <= target.Version {
+		return false, errStaleEvent
+	}
+	if ok && !target.Deleted {
+		return false, nil
+	}
+
+	missing := fmt.Errorf("%w: %s event for ID %s", ErrTargetDocumentMissing, event.Type, docID)
+	switch s.missingDocumentPolicy(event) {
+	case config.MissingDocSkip:
+		return true, nil
+	case config.MissingDocRetry:
+		return false, missing
+	case config.MissingDocPark:
+		return false, permanent("document not found", missing)
+	default:
+		return false, nil
+	}
+}
+
+// apply mencatat hasil operasi yang akan ditulis agar partial update berikutnya dalam
+// batch memakai dokumen ini sebagai dasar.
+func (d documentSnapshots) apply(op repository.BulkOperation) {
+	prev, ok := d[op.DocumentID]
+	if ok && op.Version > 0 && op.Version <= prev.Version {
+		return // Akan ditolak Elasticsearch sebagai event usang
+	}
+	source, err := toSourceMap(op.Body)
+	if err != nil {
+		return
+	}
+	deleted, _ := source["deleted"].(bool)
+	d[op.DocumentID] = repository.VersionedDocument{Source: source, Version: op.Version, Deleted: deleted}
+}
+
+// buildPartialUpdateOperation menggabungkan field di update_mask ke dokumen saat ini lalu
+// menulis ulang dokumen utuh dengan external versioning, sehingga field yang tidak
+// disebut tetap dan event usang tetap terdeteksi. Jika dokumen belum ada (kebijakan
+// upsert), dokumen baru hanya berisi id dan field di update_mask.
+func buildPartialUpdateOperation(event model.NewsEvent, current documentSnapshots, now time.Time) (repository.BulkOperation, error) {
+	docID := event.Payload.ID
+	for _, field := range event.UpdateMask {
+		if !slices.Contains(model.UpdatableFields, field) {
+			return repository.BulkOperation{}, permanent("invalid update_mask",
+				fmt.Errorf("field '%s' cannot be used in update_mask", field))
+		}
+	}
+
+	changes, err := toSourceMap(event.Payload)
+	if err != nil {
+		return repository.BulkOperation{}, err
+	}
+	merged := map[string]interface{}{"id": docID}
+	if target, ok := current[docID]; ok && !target.Deleted {
+		for field, value := range target.Source {
+			merged[field] = value
+		}
+	}
+	for _, field := range event.UpdateMask {
+		// Field yang tidak ada di payload (mis. updated_at bernilai nil) dikosongkan.
+		merged[field] = changes[field]
+	}
+	if !slices.Contains(event.UpdateMask, "updated_at") {
+		merged["updated_at"] = now
+	}
+
+	return repository.BulkOperation{
+		Action:     repository.BulkActionIndex,
+		DocumentID: docID,
+		Body:       merged,
+		Version:    event.Version,
+	}, nil
+}
+
+func toSourceMap(v interface{}) (map[string]interface{}, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal document: %w", err)
+	}
+	var source map[string]interface{}
+	if err := json.Unmarshal(raw, &source); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal document: %w", err)
+	}
+	return source, nil
+}
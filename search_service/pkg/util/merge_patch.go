@@ -0,0 +1,46 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ApplyMergePatch menerapkan JSON Merge Patch (RFC 7396) pada dokumen JSON:
+// field di patch menimpa field di target, nilai null menghapus field, dan objek
+// digabung secara rekursif.
+func ApplyMergePatch(target, patch json.RawMessage) (json.RawMessage, error) {
+	var targetValue, patchValue interface{}
+	if len(target) > 0 {
+		if err := json.Unmarshal(target, &targetValue); err != nil {
+			return nil, fmt.Errorf("invalid merge patch target: %w", err)
+		}
+	}
+	if err := json.Unmarshal(patch, &patchValue); err != nil {
+		return nil, fmt.Errorf("invalid merge patch: %w", err)
+	}
+
+	merged, err := json.Marshal(mergeValue(targetValue, patchValue))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal merged document: %w", err)
+	}
+	return merged, nil
+}
+
+func mergeValue(target, patch interface{}) interface{} {
+	patchObj, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]interface{})
+	if !ok {
+		targetObj = map[string]interface{}{}
+	}
+	for key, value := range patchObj {
+		if value == nil {
+			delete(targetObj, key)
+			continue
+		}
+		targetObj[key] = mergeValue(targetObj[key], value)
+	}
+	return targetObj
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestApplyMergePatch memakai contoh dari RFC 7396 Appendix A ditambah kasus dokumen event.
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{"replace field", `{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"add field", `{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{"null removes field", `{"a":"b"}`, `{"a":null}`, `{}`},
+		{"null removes only that field", `{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{"array is replaced", `{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{"value becomes array", `{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{"nested merge", `{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{"array of objects is replaced", `{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{"non-object target", `["a","b"]`, `{"a":"b"}`, `{"a":"b"}`},
+		{"non-object patch replaces target", `{"a":"foo"}`, `"bar"`, `"bar"`},
+		{"null patch", `{"a":"foo"}`, `null`, `null`},
+		{"nested null in new object is dropped", `{"e":null}`, `{"a":{"bb":{"ccc":null}}}`, `{"e":null,"a":{"bb":{}}}`},
+		{"empty target", ``, `{"title":"Baru"}`, `{"title":"Baru"}`},
+		{
+			name:   "event payload",
+			target: `{"id":"a1","title":"Lama","tags":["x"],"category":"politik"}`,
+			patch:  `{"title":"Baru","tags":["x","y"],"category":null}`,
+			want:   `{"id":"a1","title":"Baru","tags":["x","y"]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ApplyMergePatch(json.RawMessage(tt.target), json.RawMessage(tt.patch))
+			if err != nil {
+				t.Fatalf("ApplyMergePatch returned error: %v", err)
+			}
+			assertJSONEqual(t, got, tt.want)
+		})
+	}
+}
+
+func TestApplyMergePatchInvalidJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+	}{
+		{"invalid target", `{"a":`, `{}`},
+		{"invalid patch", `{}`, `{"a":`},
+		{"empty patch", `{}`, ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := ApplyMergePatch(json.RawMessage(tt.target), json.RawMessage(tt.patch)); err == nil {
+				t.Errorf("ApplyMergePatch(%q, %q) = %s, want error", tt.target, tt.patch, got)
+			}
+		})
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, want string) {
+	t.Helper()
+	var gotValue, wantValue interface{}
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("result is not valid JSON: %v (%s)", err, got)
+	}
+	if err := json.Unmarshal([]byte(want), &wantValue); err != nil {
+		t.Fatalf("expected value is not valid JSON: %v (%s)", err, want)
+	}
+	if !reflect.DeepEqual(gotValue, wantValue) {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}